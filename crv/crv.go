@@ -0,0 +1,63 @@
+// Package crv is the public entry point for Go programs that embed crv. It
+// runs the CLI and lets callers register additional coverage report formats,
+// which then appear in --format and take part in auto-detection.
+package crv
+
+import (
+	"io"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/app"
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
+)
+
+// Report model types produced by parsers.
+type (
+	Report       = jacoco.Report
+	Group        = jacoco.Group
+	Package      = jacoco.Package
+	Class        = jacoco.Class
+	Method       = jacoco.Method
+	Line         = jacoco.Line
+	Counter      = jacoco.Counter
+	LayerCounter = jacoco.LayerCounter
+	CounterType  = jacoco.CounterType
+)
+
+const (
+	CounterInstruction = jacoco.CounterInstruction
+	CounterBranch      = jacoco.CounterBranch
+	CounterLine        = jacoco.CounterLine
+	CounterComplexity  = jacoco.CounterComplexity
+	CounterMethod      = jacoco.CounterMethod
+	CounterClass       = jacoco.CounterClass
+)
+
+// Parser reads one coverage report format. Detect is given up to the first
+// 64 KiB of the (decompressed) input.
+type Parser = jacoco.Parser
+
+// Confidence is returned by Parser.Detect.
+type Confidence = jacoco.Confidence
+
+const (
+	ConfidenceNone = jacoco.ConfidenceNone
+	ConfidenceLow  = jacoco.ConfidenceLow
+	ConfidenceHigh = jacoco.ConfidenceHigh
+)
+
+// RegisterParser adds a format under name, replacing any parser already
+// registered with that name. Call it before Run, typically from init.
+func RegisterParser(name string, p Parser) {
+	jacoco.RegisterParser(name, p)
+}
+
+// Formats lists the registered format names.
+func Formats() []string {
+	return jacoco.ParserNames()
+}
+
+// Run executes crv with args (without the program name) and returns the
+// process exit code.
+func Run(args []string, version string, out io.Writer, errOut io.Writer) int {
+	return app.Run(args, version, out, errOut)
+}
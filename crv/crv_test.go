@@ -0,0 +1,30 @@
+package crv
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/cli"
+)
+
+type embeddedParser struct{}
+
+func (embeddedParser) Detect([]byte) Confidence { return ConfidenceNone }
+
+func (embeddedParser) Parse(io.Reader) (Report, error) { return Report{Name: "embedded"}, nil }
+
+func TestRegisterParserExtendsFormatOption(t *testing.T) {
+	RegisterParser("embedded", embeddedParser{})
+
+	if !slices.Contains(Formats(), "embedded") {
+		t.Fatalf("registered format missing: %v", Formats())
+	}
+	opts, err := cli.Parse([]string{"--format", "embedded", "report.bin"})
+	if err != nil {
+		t.Fatalf("registered format should be accepted: %v", err)
+	}
+	if opts.Format != "embedded" {
+		t.Fatalf("format mismatch: %s", opts.Format)
+	}
+}
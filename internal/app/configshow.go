@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/cli"
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/tui"
+)
+
+// writeConfig prints the effective configuration as YAML that can be copied
+// into .crv.yaml, with the source of every value as a comment.
+func writeConfig(w io.Writer, opts cli.Options, theme tui.Theme, keys tui.KeyMap) error {
+	thresholds := map[string]int{}
+	for t, threshold := range opts.Thresholds {
+		thresholds[strings.ToLower(string(t))] = threshold
+	}
+	bindings := map[string][]string{}
+	for _, action := range tui.KeyActions() {
+		bindings[action] = keys.Keys(action)
+	}
+	values := map[string]any{
+		"threshold":       opts.Threshold,
+		"thresholds":      thresholds,
+		"sort":            opts.Sort,
+		"format":          opts.Format,
+		"group-by":        opts.GroupBy,
+		"merge":           opts.Merge,
+		"report-kind":     opts.ReportKind,
+		"watch":           opts.Watch,
+		"no-color":        opts.NoColor,
+		"fold":            opts.Fold,
+		"tree":            opts.Tree,
+		"show-synthetic":  opts.ShowSynthetic,
+		"qualified-types": opts.QualifiedTypes,
+		"reports":         nonNil(opts.Paths),
+		"source-roots":    nonNil(opts.SourceRoots),
+		"exclude":         nonNil(opts.Exclude),
+		"include":         nonNil(opts.Include),
+		"theme": map[string]string{
+			"title":  theme.Title,
+			"header": theme.Header,
+			"cursor": theme.Cursor,
+			"help":   theme.Help,
+			"high":   theme.High,
+			"mid":    theme.Mid,
+			"low":    theme.Low,
+		},
+		"keys": bindings,
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range cli.ConfigKeys() {
+		var value yaml.Node
+		if err := value.Encode(values[key]); err != nil {
+			return err
+		}
+		flowSequences(&value)
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+		if value.Kind == yaml.MappingNode {
+			keyNode.LineComment = opts.Sources[key]
+		} else {
+			value.LineComment = opts.Sources[key]
+		}
+		doc.Content = append(doc.Content, keyNode, &value)
+	}
+
+	configPath := "(なし)"
+	if opts.ConfigPath != "" {
+		configPath = opts.ConfigPath
+	}
+	if _, err := fmt.Fprintf(w, "# config: %s\n", configPath); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// flowSequences writes lists on one line, like `[up, k]`.
+func flowSequences(node *yaml.Node) {
+	if node.Kind == yaml.SequenceNode {
+		node.Style = yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		flowSequences(child)
+	}
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
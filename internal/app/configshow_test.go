@@ -0,0 +1,102 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/tui"
+)
+
+func TestRunAppliesProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".crv.yaml")
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	write(configPath, "threshold: 70\nthresholds: {branch: 50}\nreports: [reports/jacoco.xml]\nkeys: {quit: x}\n")
+	write(filepath.Join(dir, "reports/jacoco.xml"), `<report name="configured"><package name="a"/></report>`)
+	nested := filepath.Join(dir, "src")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd failed: %v", err)
+	}
+	if err := os.Chdir(nested); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	origStartUIWatch := startUIWatch
+	t.Cleanup(func() {
+		_ = os.Chdir(origWD)
+		startUIWatch = origStartUIWatch
+	})
+	startUIWatch = func(report jacoco.Report, cfg tui.Config, _ func() (jacoco.Report, error), _ func() (bool, error)) error {
+		if report.Name != "configured" {
+			t.Fatalf("report from config should be loaded, got %q", report.Name)
+		}
+		if cfg.Threshold != 65 || cfg.Thresholds[jacoco.CounterBranch] != 50 {
+			t.Fatalf("thresholds mismatch: %+v", cfg)
+		}
+		if keys := cfg.Keys.Keys("quit"); len(keys) != 1 || keys[0] != "x" {
+			t.Fatalf("key bindings mismatch: %v", keys)
+		}
+		return nil
+	}
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"-t", "65"}, "dev", &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d (stderr=%q)", code, errOut.String())
+	}
+
+	out.Reset()
+	if code := Run([]string{"config", "show", "-t", "65"}, "dev", &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d (stderr=%q)", code, errOut.String())
+	}
+	for _, want := range []string{
+		"# config: " + configPath + "\n",
+		"threshold: 65 # flag\n",
+		"thresholds: # " + configPath + "\n  branch: 50\n",
+		"sort: name # default\n",
+		"reports: [" + filepath.Join(dir, "reports/jacoco.xml") + "] # " + configPath + "\n",
+		"  quit: [x]\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("config show should contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunRejectsInvalidProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".crv.yaml"), []byte("keys: {quit: s}\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd failed: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(origWD) })
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"config", "show"}, "dev", &out, &errOut); code != 2 {
+		t.Fatalf("expected 2, got %d", code)
+	}
+	if !strings.Contains(errOut.String(), "keys:") {
+		t.Fatalf("error should name the setting: %q", errOut.String())
+	}
+}
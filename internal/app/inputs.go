@@ -0,0 +1,187 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
+)
+
+// expandInputs resolves command line paths into report files. Directories are
+// walked for reports of format, and glob patterns (including ** for any number
+// of directories) are expanded. The result keeps argument order and drops
+// duplicates.
+func expandInputs(args []string, format string) ([]string, error) {
+	seen := map[string]struct{}{}
+	paths := make([]string, 0, len(args))
+	add := func(p string) {
+		key := filepath.Clean(p)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		paths = append(paths, p)
+	}
+
+	for _, arg := range args {
+		if jacoco.IsStream(arg) {
+			add(arg)
+			continue
+		}
+		info, err := os.Stat(arg)
+		switch {
+		case err == nil && info.IsDir():
+			found, err := walkReportDir(arg, format)
+			if err != nil {
+				return nil, err
+			}
+			if len(found) == 0 {
+				return nil, fmt.Errorf("ディレクトリ内にカバレッジレポートが見つかりません: %s", arg)
+			}
+			for _, p := range found {
+				add(p)
+			}
+		case err == nil:
+			add(arg)
+		case errors.Is(err, fs.ErrNotExist) && hasGlobMeta(arg):
+			matches, err := expandGlob(arg)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("パターンに一致するファイルがありません: %s", arg)
+			}
+			for _, p := range matches {
+				add(p)
+			}
+		default:
+			// Leave missing files to the parser so the error names the path.
+			add(arg)
+		}
+	}
+	return paths, nil
+}
+
+// reportNamePatterns are the file names the built-in formats are usually
+// written under, including the default locations that auto-detection searches.
+var reportNamePatterns = map[string][]string{
+	string(jacoco.FormatJaCoCo):    {"jacoco*.xml"},
+	string(jacoco.FormatCobertura): {"cobertura*.xml", "coverage*.xml", "*.cobertura.xml"},
+	string(jacoco.FormatLCOV):      {"lcov*", "*.info", "_coverage_report.dat"},
+}
+
+// isReportName reports whether a file name is a usual name for reports of
+// format. Compression suffixes are ignored.
+func isReportName(name, format string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".gz", ".zst", ".bz2"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	for _, p := range reportNamePatterns[format] {
+		if matched, _ := path.Match(p, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// walkReportDir lists the files under dir that hold a report of format. Every
+// regular file is sniffed from a bounded prefix; with an explicit format only
+// that parser is asked, and files with one of its usual names are taken
+// unread. Hidden directories such as .git are skipped.
+func walkReportDir(dir, format string) ([]string, error) {
+	var found []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if format == string(jacoco.FormatAuto) {
+			if _, err := jacoco.DetectFormatFile(p); err == nil {
+				found = append(found, p)
+			}
+			return nil
+		}
+		if isReportName(d.Name(), format) || jacoco.ClaimsFile(p, jacoco.InputFormat(format)) {
+			found = append(found, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(found)
+	return found, nil
+}
+
+func hasGlobMeta(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// expandGlob matches pattern against the file system. Each path segment is
+// matched with path.Match, and a ** segment matches zero or more directories.
+func expandGlob(pattern string) ([]string, error) {
+	slashed := filepath.ToSlash(pattern)
+	segments := strings.Split(slashed, "/")
+	baseLen := 0
+	for baseLen < len(segments) && !hasGlobMeta(segments[baseLen]) {
+		baseLen++
+	}
+	for _, seg := range segments[baseLen:] {
+		if seg != "**" {
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("不正なパターンです: %s: %w", pattern, err)
+			}
+		}
+	}
+
+	base := strings.Join(segments[:baseLen], "/")
+	root := filepath.FromSlash(base)
+	switch {
+	case base == "" && strings.HasPrefix(slashed, "/"):
+		root = string(filepath.Separator)
+	case base == "":
+		root = "."
+	}
+	if _, err := os.Stat(root); err != nil {
+		return nil, nil
+	}
+
+	rest := segments[baseLen:]
+	var matches []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if jacoco.MatchSegments(rest, strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
+)
+
+func writeInputFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+}
+
+func TestExpandInputsRecursiveGlob(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "build/a/jacoco.xml")
+	b := filepath.Join(dir, "build/b/reports/jacoco.xml")
+	writeInputFile(t, a, `<report name="a"/>`)
+	writeInputFile(t, b, `<report name="b"/>`)
+	writeInputFile(t, filepath.Join(dir, "build/b/other.xml"), `<report name="x"/>`)
+
+	got, err := expandInputs([]string{filepath.Join(dir, "build/**/jacoco.xml")}, "auto")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{a, b}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestExpandInputsWalksDirectoryForRecognizedReports(t *testing.T) {
+	dir := t.TempDir()
+	xml := filepath.Join(dir, "reports/jacoco.xml")
+	info := filepath.Join(dir, "reports/web/lcov.info")
+	writeInputFile(t, xml, `<report name="a"/>`)
+	writeInputFile(t, info, "TN:\nSF:a.ts\nDA:1,1\nend_of_record\n")
+	writeInputFile(t, filepath.Join(dir, "reports/README.md"), "# notes\n")
+	writeInputFile(t, filepath.Join(dir, "reports/pom.xml"), `<project/>`)
+	writeInputFile(t, filepath.Join(dir, "reports/.cache/jacoco.xml"), `<report name="hidden"/>`)
+
+	got, err := expandInputs([]string{filepath.Join(dir, "reports")}, "auto")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{xml, info}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestExpandInputsWalksDirectoryByNameForExplicitFormat(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "target/site/jacoco/jacoco.xml.gz")
+	writeInputFile(t, report, "not sniffed")
+	writeInputFile(t, filepath.Join(dir, "target/pom.xml"), `<project/>`)
+	writeInputFile(t, filepath.Join(dir, "web/lcov.info"), "TN:\nSF:a.ts\nend_of_record\n")
+
+	got, err := expandInputs([]string{dir}, "jacoco")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{report}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestExpandInputsWalksDirectoryForDefaultReportLocations(t *testing.T) {
+	dir := t.TempDir()
+	bazel := filepath.Join(dir, "bazel-out/_coverage/_coverage_report.dat")
+	gradle := filepath.Join(dir, "build/reports/jacoco/testCodeCoverageReport/testCodeCoverageReport.xml")
+	writeInputFile(t, bazel, "SF:src/a.cc\nDA:1,1\nend_of_record\n")
+	writeInputFile(t, gradle, `<report name="aggregate"/>`)
+
+	for _, tc := range []struct {
+		arg, format string
+		want        []string
+	}{
+		{arg: "bazel-out", format: "auto", want: []string{bazel}},
+		{arg: "bazel-out", format: "lcov", want: []string{bazel}},
+		{arg: "build", format: "auto", want: []string{gradle}},
+		{arg: "build", format: "jacoco", want: []string{gradle}},
+	} {
+		got, err := expandInputs([]string{filepath.Join(dir, tc.arg)}, tc.format)
+		if err != nil {
+			t.Fatalf("%s (%s): unexpected error: %v", tc.arg, tc.format, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s (%s): got %v, want %v", tc.arg, tc.format, got, tc.want)
+		}
+	}
+}
+
+type firmwareParser struct{}
+
+func (firmwareParser) Detect(prefix []byte) jacoco.Confidence {
+	if strings.HasPrefix(string(prefix), "FIRMWARE COVERAGE") {
+		return jacoco.ConfidenceHigh
+	}
+	return jacoco.ConfidenceNone
+}
+
+func (firmwareParser) Parse(io.Reader) (jacoco.Report, error) {
+	return jacoco.Report{Name: "firmware"}, nil
+}
+
+func TestExpandInputsWalksDirectoryForPluginFormats(t *testing.T) {
+	jacoco.RegisterParser("firmware-test", firmwareParser{})
+	dir := t.TempDir()
+	report := filepath.Join(dir, "out/run1.cov")
+	writeInputFile(t, report, "FIRMWARE COVERAGE v1\n")
+	writeInputFile(t, filepath.Join(dir, "out/notes.txt"), "hello\n")
+
+	for _, format := range []string{"auto", "firmware-test"} {
+		got, err := expandInputs([]string{dir}, format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if want := []string{report}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %v, want %v", format, got, want)
+		}
+	}
+}
+
+func TestExpandInputsDeduplicatesAndKeepsOrder(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.xml")
+	b := filepath.Join(dir, "b.xml")
+	writeInputFile(t, a, `<report name="a"/>`)
+	writeInputFile(t, b, `<report name="b"/>`)
+
+	got, err := expandInputs([]string{b, filepath.Join(dir, "*.xml"), "-"}, "auto")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{b, a, "-"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestExpandInputsFailsWhenGlobMatchesNothing(t *testing.T) {
+	if _, err := expandInputs([]string{filepath.Join(t.TempDir(), "**/jacoco.xml")}, "auto"); err == nil {
+		t.Fatal("expected error for unmatched glob")
+	}
+}
+
+func TestExpandInputsFailsForDirectoryWithoutReports(t *testing.T) {
+	dir := t.TempDir()
+	writeInputFile(t, filepath.Join(dir, "notes.txt"), "hello\n")
+	if _, err := expandInputs([]string{dir}, "auto"); err == nil {
+		t.Fatal("expected error for directory without reports")
+	}
+}
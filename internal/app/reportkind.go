@@ -0,0 +1,98 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/reportpath"
+)
+
+// reportKindAll keeps the detected reports of every kind.
+const reportKindAll = "all"
+
+var (
+	stdinIsTerminal  = isTerminal
+	chooseReportKind = func(kinds []string, errOut io.Writer) (string, error) {
+		return promptReportKind(os.Stdin, errOut, kinds)
+	}
+)
+
+// selectCandidates narrows detected reports down to one report kind. Without
+// --report-kind the user is asked when several kinds were found, or the first
+// kind is taken when asking is not possible.
+func selectCandidates(candidates []reportpath.Candidate, kind string, interactive bool, errOut io.Writer) ([]reportpath.Candidate, error) {
+	if kind == reportKindAll {
+		return candidates, nil
+	}
+	kinds := reportpath.CandidateKinds(candidates)
+	if kind == "auto" {
+		if len(kinds) <= 1 {
+			return candidates, nil
+		}
+		if interactive {
+			chosen, err := chooseReportKind(kinds, errOut)
+			if err != nil {
+				return nil, err
+			}
+			kind = chosen
+		} else {
+			kind = kinds[0]
+			_, _ = fmt.Fprintf(errOut, "warning: 複数のレポート種別が見つかりました（%s）。%s を表示します（--report-kind で変更できます）\n", strings.Join(kinds, " / "), kind)
+		}
+		if kind == reportKindAll {
+			return candidates, nil
+		}
+	}
+	selected := reportpath.FilterKind(candidates, kind)
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%s のレポートが見つかりません（検出: %s）", kind, strings.Join(kinds, " / "))
+	}
+	return selected, nil
+}
+
+// promptReportKind asks for one of kinds, or all of them, by number. An
+// empty answer picks the first kind.
+func promptReportKind(in io.Reader, out io.Writer, kinds []string) (string, error) {
+	choices := append(append([]string{}, kinds...), reportKindAll)
+	_, _ = fmt.Fprintln(out, "複数のレポート種別が見つかりました:")
+	for i, choice := range choices {
+		_, _ = fmt.Fprintf(out, "  %d) %s\n", i+1, choice)
+	}
+	reader := bufio.NewReader(in)
+	for {
+		_, _ = fmt.Fprintf(out, "表示するレポートを選択してください [1-%d] (default: 1): ", len(choices))
+		line, err := reader.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "" && err == nil {
+			return choices[0], nil
+		}
+		if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1], nil
+		}
+		for _, choice := range choices {
+			if answer == choice {
+				return choice, nil
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", errors.New("レポート種別が選択されませんでした")
+			}
+			return "", err
+		}
+		_, _ = fmt.Fprintf(out, "1 から %d の番号を入力してください\n", len(choices))
+	}
+}
+
+func isTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/tui"
+)
+
+func TestRunSelectsReportKind(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	write(filepath.Join(dir, "target/site/jacoco/jacoco.xml"), `<report name="unit"><package name="unit"/></report>`)
+	write(filepath.Join(dir, "target/site/jacoco-it/jacoco.xml"), `<report name="it"><package name="it"/></report>`)
+
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd failed: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	origStartUIWatch, origTerminal, origChoose := startUIWatch, stdinIsTerminal, chooseReportKind
+	t.Cleanup(func() {
+		_ = os.Chdir(origWD)
+		startUIWatch, stdinIsTerminal, chooseReportKind = origStartUIWatch, origTerminal, origChoose
+	})
+
+	var packages []string
+	startUIWatch = func(report jacoco.Report, _ tui.Config, _ func() (jacoco.Report, error), _ func() (bool, error)) error {
+		packages = packages[:0]
+		for _, pkg := range report.Packages {
+			packages = append(packages, pkg.Name)
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		terminal bool
+		chosen   string
+		want     string
+	}{
+		{name: "first kind without terminal", want: "unit"},
+		{name: "prompt on terminal", terminal: true, chosen: "report-integration", want: "it"},
+		{name: "prompt all", terminal: true, chosen: "all", want: "it,unit"},
+		{name: "flag", args: []string{"--report-kind", "report-integration"}, terminal: true, want: "it"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdinIsTerminal = func() bool { return tt.terminal }
+			chooseReportKind = func(kinds []string, _ io.Writer) (string, error) {
+				if tt.chosen == "" {
+					t.Fatalf("prompt should not be shown")
+				}
+				if strings.Join(kinds, ",") != "report,report-integration" {
+					t.Fatalf("unexpected kinds: %v", kinds)
+				}
+				return tt.chosen, nil
+			}
+			var out, errOut bytes.Buffer
+			if code := Run(tt.args, "dev", &out, &errOut); code != 0 {
+				t.Fatalf("expected 0, got %d (stderr=%q)", code, errOut.String())
+			}
+			if got := strings.Join(packages, ","); got != tt.want {
+				t.Fatalf("packages mismatch: got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"--report-kind", "report-aggregate"}, "dev", &out, &errOut); code != 1 {
+		t.Fatalf("expected 1 for missing kind, got %d", code)
+	}
+}
+
+func TestRunExplainPrintsDetectionWithoutUI(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "coverage/lcov.info")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("TN:\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd failed: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	origStartUIWatch := startUIWatch
+	t.Cleanup(func() {
+		_ = os.Chdir(origWD)
+		startUIWatch = origStartUIWatch
+	})
+	startUIWatch = func(jacoco.Report, tui.Config, func() (jacoco.Report, error), func() (bool, error)) error {
+		t.Fatal("explain should not start the UI")
+		return nil
+	}
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"--explain"}, "dev", &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d (stderr=%q)", code, errOut.String())
+	}
+	for _, want := range []string{"✓ coverage/lcov.info (Jest / nyc", "表示するレポート:\n  1. coverage/lcov.info\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output should contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestPromptReportKind(t *testing.T) {
+	kinds := []string{"report", "report-integration"}
+	var out bytes.Buffer
+	got, err := promptReportKind(strings.NewReader("5\n2\n"), &out, kinds)
+	if err != nil || got != "report-integration" {
+		t.Fatalf("unexpected choice: %q (%v)", got, err)
+	}
+	if !strings.Contains(out.String(), "3) all") || !strings.Contains(out.String(), "1 から 3 の番号") {
+		t.Fatalf("unexpected prompt: %q", out.String())
+	}
+
+	if got, err := promptReportKind(strings.NewReader("\n"), io.Discard, kinds); err != nil || got != "report" {
+		t.Fatalf("empty answer should pick the first kind: %q (%v)", got, err)
+	}
+	if _, err := promptReportKind(strings.NewReader(""), io.Discard, kinds); err == nil {
+		t.Fatal("expected error on EOF")
+	}
+}
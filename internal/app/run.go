@@ -1,11 +1,15 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/izuno4t/coverage-report-viewer-cli/internal/cli"
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/config"
 	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
 	"github.com/izuno4t/coverage-report-viewer-cli/internal/reportpath"
 	"github.com/izuno4t/coverage-report-viewer-cli/internal/tui"
@@ -31,34 +35,105 @@ func Run(args []string, version string, out io.Writer, errOut io.Writer) int {
 		return 0
 	}
 
-	reportPath := opts.Path
-	reportPaths := make([]string, 0, 1)
-	if reportPath == "" {
-		cwd, err := os.Getwd()
+	cwd, err := os.Getwd()
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "error: カレントディレクトリ取得に失敗しました: %v\n", err)
+		return 1
+	}
+	file, err := config.Discover(cwd)
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "error: 設定ファイルの読み込みに失敗しました: %v\n", err)
+		return 2
+	}
+	if err := opts.ApplyConfig(file); err != nil {
+		_, _ = fmt.Fprintf(errOut, "error: %s: %v\n", file.Path, err)
+		return 2
+	}
+	theme, err := tui.NewTheme(opts.Theme)
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "error: %s: theme: %v\n", opts.ConfigPath, err)
+		return 2
+	}
+	keys, err := tui.NewKeyMap(opts.Keys)
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "error: %s: keys: %v\n", opts.ConfigPath, err)
+		return 2
+	}
+	filter, err := jacoco.NewFilter(opts.Exclude, opts.Include)
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "error: %v\n", err)
+		return 2
+	}
+	if opts.Command == cli.CommandConfigShow {
+		if err := writeConfig(out, opts, theme, keys); err != nil {
+			_, _ = fmt.Fprintf(errOut, "error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if opts.Format == string(jacoco.FormatAuto) {
+		// Auto-detection consults the plugins as well as the built-in parsers.
+		jacoco.RegisterPathParsers()
+	}
+	reportPaths, err := expandInputs(opts.Paths, opts.Format)
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "error: %v\n", err)
+		return 1
+	}
+	inputs := make([]reportInput, 0, len(reportPaths))
+	for _, path := range reportPaths {
+		inputs = append(inputs, reportInput{path: path})
+	}
+	if len(inputs) == 0 {
+		detectOpts := reportpath.Options{Properties: opts.Properties}
+		if opts.Explain {
+			detectOpts.Trace = out
+		}
+		candidates, err := reportpath.DetectCandidates(cwd, detectOpts)
 		if err != nil {
-			_, _ = fmt.Fprintf(errOut, "error: カレントディレクトリ取得に失敗しました: %v\n", err)
+			_, _ = fmt.Fprintf(errOut, "error: カバレッジレポートが見つかりません: %v\n", err)
+			printDetectionHint(errOut, err)
 			return 1
 		}
-		reportPaths, err = reportpath.DetectAll(cwd)
+		candidates, err = selectCandidates(candidates, opts.ReportKind, !opts.Explain && stdinIsTerminal(), errOut)
 		if err != nil {
-			_, _ = fmt.Fprintf(errOut, "error: JaCoCo XML が見つかりません: %v\n", err)
-			_, _ = fmt.Fprintln(errOut, "hint: path を指定するか、target/site/jacoco/jacoco.xml の生成を確認してください")
+			_, _ = fmt.Fprintf(errOut, "error: %v\n", err)
 			return 1
 		}
-	} else {
-		reportPaths = append(reportPaths, reportPath)
+		for _, c := range candidates {
+			inputs = append(inputs, reportInput{path: relativePath(cwd, c.Path), module: c.Module})
+		}
+		reportPaths = inputPaths(inputs)
+	} else if opts.Explain {
+		if opts.Sources["reports"] == cli.SourceArgs {
+			_, _ = fmt.Fprintln(out, "path が指定されているため自動検出は行いません")
+		} else {
+			_, _ = fmt.Fprintf(out, "%s の reports が指定されているため自動検出は行いません\n", opts.ConfigPath)
+		}
+	}
+	if opts.Explain {
+		_, _ = fmt.Fprintln(out, "表示するレポート:")
+		for i, in := range inputs {
+			_, _ = fmt.Fprintf(out, "  %d. %s\n", i+1, inputLabel(in.path))
+		}
+		return 0
 	}
 
+	// Per-line data is only read when merging several inputs or applying
+	// source pragmas.
+	parseOpts := jacoco.ParseOptions{Lines: len(inputs) > 1 || len(opts.SourceRoots) > 0}
 	loadReport := func() (jacoco.Report, error) {
-		reports := make([]jacoco.Report, 0, len(reportPaths))
-		for _, path := range reportPaths {
-			report, err := jacoco.ParseWithFormatFile(path, jacoco.InputFormat(opts.Format))
+		reports := make([]jacoco.Report, 0, len(inputs))
+		for _, in := range inputs {
+			report, err := jacoco.ParseWithFormatFile(in.path, jacoco.InputFormat(opts.Format), parseOpts)
 			if err != nil {
-				return jacoco.Report{}, err
+				return jacoco.Report{}, fmt.Errorf("%s: %w", inputLabel(in.path), err)
 			}
-			reports = append(reports, report)
+			report = jacoco.ApplySourcePragmas(report, opts.SourceRoots)
+			reports = append(reports, jacoco.FilterReport(report, filter))
 		}
-		return jacoco.MergeReports(reports...), nil
+		return combineReports(reports, inputs, opts.GroupBy, jacoco.MergeStrategy(opts.Merge)), nil
 	}
 	report, err := loadReport()
 	if err != nil {
@@ -67,18 +142,36 @@ func Run(args []string, version string, out io.Writer, errOut io.Writer) int {
 	}
 
 	uiConfig := tui.Config{
-		Threshold: opts.Threshold,
-		Sort:      opts.Sort,
-		NoColor:   opts.NoColor,
-		Watch:     opts.Watch,
+		Threshold:      opts.Threshold,
+		Thresholds:     opts.Thresholds,
+		Sort:           opts.Sort,
+		NoColor:        opts.NoColor,
+		Watch:          opts.Watch,
+		Fold:           opts.Fold,
+		Tree:           opts.Tree,
+		ShowSynthetic:  opts.ShowSynthetic,
+		QualifiedTypes: opts.QualifiedTypes,
+		Theme:          theme,
+		Keys:           keys,
 	}
 
-	probe, err := newReportUpdateProbe(reportPaths)
-	if err != nil {
-		_, _ = fmt.Fprintf(errOut, "error: 監視対象レポートの状態取得に失敗しました: %v\n", err)
-		return 1
+	reloadFn := loadReport
+	var probe func() (bool, error)
+	if hasStream(reportPaths) {
+		// A stream can be read only once, so there is nothing to watch or reload.
+		if opts.Watch {
+			_, _ = fmt.Fprintln(errOut, "warning: 標準入力から読み込む場合 --watch は無効です")
+		}
+		uiConfig.Watch = false
+		reloadFn = nil
+	} else {
+		probe, err = newReportUpdateProbe(reportPaths)
+		if err != nil {
+			_, _ = fmt.Fprintf(errOut, "error: 監視対象レポートの状態取得に失敗しました: %v\n", err)
+			return 1
+		}
 	}
-	if err := startUIWatch(report, uiConfig, loadReport, probe); err != nil {
+	if err := startUIWatch(report, uiConfig, reloadFn, probe); err != nil {
 		_, _ = fmt.Fprintf(errOut, "error: TUI 起動に失敗しました: %v\n", err)
 		return 1
 	}
@@ -86,3 +179,113 @@ func Run(args []string, version string, out io.Writer, errOut io.Writer) int {
 	_, _ = fmt.Fprintln(out, "crv finished")
 	return 0
 }
+
+// printDetectionHint lists, per tool, the locations that auto-detection
+// searched.
+func printDetectionHint(errOut io.Writer, err error) {
+	var notFound *reportpath.NotFoundError
+	if !errors.As(err, &notFound) {
+		_, _ = fmt.Fprintln(errOut, "hint: path を指定してください")
+		return
+	}
+	_, _ = fmt.Fprintln(errOut, "hint: path を指定するか、次のいずれかの場所にレポートを生成してください")
+	var tools []string
+	patterns := map[string][]string{}
+	for _, loc := range notFound.Searched {
+		if _, ok := patterns[loc.Tool]; !ok {
+			tools = append(tools, loc.Tool)
+		}
+		patterns[loc.Tool] = append(patterns[loc.Tool], loc.Pattern)
+	}
+	for _, tool := range tools {
+		_, _ = fmt.Fprintf(errOut, "  %s: %s\n", tool, strings.Join(patterns[tool], ", "))
+	}
+}
+
+// reportInput is a report file to load. module is known when the path came
+// from project detection.
+type reportInput struct {
+	path   string
+	module string
+}
+
+func inputPaths(inputs []reportInput) []string {
+	paths := make([]string, len(inputs))
+	for i, in := range inputs {
+		paths[i] = in.path
+	}
+	return paths
+}
+
+// combineReports builds the tree shown in the UI from one report per input.
+// groupBy "auto" keeps a flat merge for a single format and splits the tree
+// by format as soon as the inputs mix formats.
+func combineReports(reports []jacoco.Report, inputs []reportInput, groupBy string, strategy jacoco.MergeStrategy) jacoco.Report {
+	if groupBy == "auto" {
+		groupBy = "none"
+		if jacoco.MixedFormats(reports) {
+			groupBy = "format"
+		}
+	}
+
+	labels := make([]string, len(reports))
+	for i, report := range reports {
+		switch groupBy {
+		case "input":
+			labels[i] = inputLabel(inputs[i].path)
+		case "module":
+			labels[i] = moduleLabel(inputs[i])
+		case "format":
+			labels[i] = reportFormat(report)
+		default:
+			return jacoco.MergeReportsWith(strategy, reports...)
+		}
+	}
+	return jacoco.GroupReports(strategy, reports, labels)
+}
+
+// moduleLabel names the Maven module of an input, looking for the owning
+// pom.xml when the path was given on the command line.
+func moduleLabel(in reportInput) string {
+	if in.module != "" {
+		return in.module
+	}
+	if !jacoco.IsStream(in.path) {
+		if module, ok := reportpath.ModuleOf(in.path); ok {
+			return module
+		}
+	}
+	return inputLabel(in.path)
+}
+
+func reportFormat(report jacoco.Report) string {
+	if len(report.Inputs) == 0 {
+		return "unknown"
+	}
+	return string(report.Inputs[0].Format)
+}
+
+func inputLabel(path string) string {
+	if jacoco.IsStream(path) {
+		return "(stdin)"
+	}
+	return path
+}
+
+func hasStream(paths []string) bool {
+	for _, path := range paths {
+		if jacoco.IsStream(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// relativePath shortens a detected path below cwd so that group and layer
+// names stay readable.
+func relativePath(cwd, path string) string {
+	if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
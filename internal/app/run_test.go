@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
@@ -54,6 +55,9 @@ func TestRunReturnsErrorWhenReportMissing(t *testing.T) {
 	if code != 1 {
 		t.Fatalf("expected 1, got %d", code)
 	}
+	if !strings.Contains(errOut.String(), "pytest-cov: coverage.xml") || !strings.Contains(errOut.String(), "Jest / nyc: coverage/lcov.info") {
+		t.Fatalf("hint should list the searched locations: %q", errOut.String())
+	}
 }
 
 func TestRunAutoDetectsReportPath(t *testing.T) {
@@ -308,3 +312,207 @@ func TestRunWithFormatLCOV(t *testing.T) {
 		t.Fatal("startUIWatch should be called")
 	}
 }
+
+func TestRunReadsStdinWithoutWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stdin.xml")
+	if err := os.WriteFile(path, []byte("<report name=\"piped\"/>"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer f.Close()
+	origStdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = origStdin
+	})
+
+	origStartUIWatch := startUIWatch
+	t.Cleanup(func() {
+		startUIWatch = origStartUIWatch
+	})
+	called := false
+	startUIWatch = func(report jacoco.Report, cfg tui.Config, reloadFn func() (jacoco.Report, error), probeFn func() (bool, error)) error {
+		called = true
+		if report.Name != "piped" {
+			t.Fatalf("unexpected report name: %s", report.Name)
+		}
+		if cfg.Watch || reloadFn != nil || probeFn != nil {
+			t.Fatal("watch should be disabled for stdin input")
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	code := Run([]string{"--watch", "-"}, "dev", &out, &errOut)
+	if code != 0 {
+		t.Fatalf("expected 0, got %d (stderr=%q)", code, errOut.String())
+	}
+	if !called {
+		t.Fatal("startUIWatch should be called")
+	}
+	if errOut.Len() == 0 {
+		t.Fatal("expected warning about --watch with stdin")
+	}
+}
+
+func TestRunGroupsMixedFormatsByFormat(t *testing.T) {
+	dir := t.TempDir()
+	jacocoPath := filepath.Join(dir, "jacoco.xml")
+	lcovPath := filepath.Join(dir, "lcov.info")
+	jacocoXML := `<report name="backend"><package name="com/example"><class name="com/example/A"><method name="f" desc="()V"><counter type="INSTRUCTION" missed="1" covered="9"/><counter type="LINE" missed="1" covered="3"/></method></class></package></report>`
+	if err := os.WriteFile(jacocoPath, []byte(jacocoXML), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := os.WriteFile(lcovPath, []byte("TN:\nSF:web/app.ts\nDA:1,1\nDA:2,0\nend_of_record\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	origStartUIWatch := startUIWatch
+	t.Cleanup(func() {
+		startUIWatch = origStartUIWatch
+	})
+	called := false
+	startUIWatch = func(report jacoco.Report, _ tui.Config, _ func() (jacoco.Report, error), _ func() (bool, error)) error {
+		called = true
+		if len(report.Groups) != 2 || report.Groups[0].Name != "jacoco" || report.Groups[1].Name != "lcov" {
+			t.Fatalf("expected one group per format, got %+v", report.Groups)
+		}
+		if _, ok := report.Counter(jacoco.CounterInstruction); ok {
+			t.Fatalf("root should only keep format neutral counters: %+v", report.Counters)
+		}
+		line, ok := report.Counter(jacoco.CounterLine)
+		if !ok || line.Missed != 2 || line.Covered != 4 {
+			t.Fatalf("unexpected root line counter: %+v", report.Counters)
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	code := Run([]string{jacocoPath, lcovPath}, "dev", &out, &errOut)
+	if code != 0 {
+		t.Fatalf("expected 0, got %d (stderr=%q)", code, errOut.String())
+	}
+	if !called {
+		t.Fatal("startUIWatch should be called")
+	}
+}
+
+func TestRunGroupsByInput(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "a.xml"), filepath.Join(dir, "b.xml")}
+	for _, path := range paths {
+		if err := os.WriteFile(path, []byte(`<report name="x"><counter type="LINE" missed="1" covered="1"/></report>`), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	origStartUIWatch := startUIWatch
+	t.Cleanup(func() {
+		startUIWatch = origStartUIWatch
+	})
+	startUIWatch = func(report jacoco.Report, _ tui.Config, _ func() (jacoco.Report, error), _ func() (bool, error)) error {
+		if len(report.Groups) != 2 || report.Groups[0].Name != paths[0] {
+			t.Fatalf("expected one group per input, got %+v", report.Groups)
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	code := Run(append([]string{"--group-by", "input"}, paths...), "dev", &out, &errOut)
+	if code != 0 {
+		t.Fatalf("expected 0, got %d (stderr=%q)", code, errOut.String())
+	}
+}
+
+func TestRunExpandsDirectoryAndWatchesEveryReport(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a/jacoco.xml")
+	b := filepath.Join(dir, "b/jacoco.xml")
+	for _, path := range []string{a, b} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(`<report name="x"/>`), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	origStartUIWatch := startUIWatch
+	t.Cleanup(func() {
+		startUIWatch = origStartUIWatch
+	})
+	var probe func() (bool, error)
+	startUIWatch = func(report jacoco.Report, _ tui.Config, _ func() (jacoco.Report, error), probeFn func() (bool, error)) error {
+		if len(report.Inputs) != 2 {
+			t.Fatalf("expected both reports to be loaded, got %+v", report.Inputs)
+		}
+		probe = probeFn
+		return nil
+	}
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	code := Run([]string{"--watch", dir}, "dev", &out, &errOut)
+	if code != 0 {
+		t.Fatalf("expected 0, got %d (stderr=%q)", code, errOut.String())
+	}
+	if err := os.WriteFile(b, []byte(`<report name="changed"/>`), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	changed, err := probe()
+	if err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if !changed {
+		t.Fatal("probe should notice changes to any expanded report")
+	}
+}
+
+func TestRunGroupsByMavenModule(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	// Both modules use the same package name, which must not be combined.
+	report := `<report name="x"><package name="com/example"><class name="com/example/A"><counter type="LINE" missed="1" covered="1"/></class></package></report>`
+	write(filepath.Join(dir, "pom.xml"), `<project><modules><module>api</module><module>core</module></modules></project>`)
+	write(filepath.Join(dir, "api/pom.xml"), `<project><artifactId>shop-api</artifactId></project>`)
+	write(filepath.Join(dir, "core/pom.xml"), `<project><artifactId>shop-core</artifactId></project>`)
+	write(filepath.Join(dir, "api/target/site/jacoco/jacoco.xml"), report)
+	write(filepath.Join(dir, "core/target/site/jacoco/jacoco.xml"), report)
+
+	origStartUIWatch := startUIWatch
+	t.Cleanup(func() {
+		startUIWatch = origStartUIWatch
+	})
+	startUIWatch = func(report jacoco.Report, _ tui.Config, _ func() (jacoco.Report, error), _ func() (bool, error)) error {
+		if len(report.Groups) != 2 || report.Groups[0].Name != "shop-api" || report.Groups[1].Name != "shop-core" {
+			t.Fatalf("expected one group per module, got %+v", report.Groups)
+		}
+		line, _ := report.Groups[0].Counter(jacoco.CounterLine)
+		if line.Total() != 2 {
+			t.Fatalf("module counters should not combine packages across modules: %+v", line)
+		}
+		return nil
+	}
+
+	// Explicit paths resolve their module from the closest pom.xml.
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	code := Run([]string{"--group-by", "module", filepath.Join(dir, "api/target/site/jacoco/jacoco.xml"), filepath.Join(dir, "core/target/site/jacoco/jacoco.xml")}, "dev", &out, &errOut)
+	if code != 0 {
+		t.Fatalf("expected 0, got %d (stderr=%q)", code, errOut.String())
+	}
+}
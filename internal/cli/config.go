@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/config"
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
+)
+
+var thresholdCounterTypes = []jacoco.CounterType{
+	jacoco.CounterInstruction,
+	jacoco.CounterBranch,
+	jacoco.CounterLine,
+	jacoco.CounterComplexity,
+	jacoco.CounterMethod,
+	jacoco.CounterClass,
+}
+
+// ApplyConfig fills the options that were not given on the command line from
+// a project configuration file and validates the result.
+func (opts *Options) ApplyConfig(file config.File) error {
+	if file.Path == "" {
+		return nil
+	}
+	opts.ConfigPath = file.Path
+	if opts.Sources == nil {
+		opts.Sources = map[string]string{}
+	}
+
+	set := func(key string, present bool, apply func()) {
+		if !present || opts.Sources[key] == SourceFlag || opts.Sources[key] == SourceArgs {
+			return
+		}
+		apply()
+		opts.Sources[key] = file.Path
+	}
+	set("threshold", file.Threshold != nil, func() { opts.Threshold = *file.Threshold })
+	set("sort", file.Sort != nil, func() { opts.Sort = *file.Sort })
+	set("format", file.Format != nil, func() { opts.Format = *file.Format })
+	set("group-by", file.GroupBy != nil, func() { opts.GroupBy = *file.GroupBy })
+	set("merge", file.Merge != nil, func() { opts.Merge = *file.Merge })
+	set("report-kind", file.ReportKind != nil, func() { opts.ReportKind = *file.ReportKind })
+	set("watch", file.Watch != nil, func() { opts.Watch = *file.Watch })
+	set("no-color", file.NoColor != nil, func() { opts.NoColor = *file.NoColor })
+	set("fold", file.Fold != nil, func() { opts.Fold = *file.Fold })
+	set("tree", file.Tree != nil, func() { opts.Tree = *file.Tree })
+	set("show-synthetic", file.ShowSynthetic != nil, func() { opts.ShowSynthetic = *file.ShowSynthetic })
+	set("qualified-types", file.QualifiedTypes != nil, func() { opts.QualifiedTypes = *file.QualifiedTypes })
+	set("reports", len(file.Reports) > 0, func() { opts.Paths = file.Reports })
+	set("source-roots", file.SourceRoots != nil, func() { opts.SourceRoots = file.SourceRoots })
+	set("exclude", file.Exclude != nil, func() { opts.Exclude = file.Exclude })
+	set("include", file.Include != nil, func() { opts.Include = file.Include })
+	set("theme", file.Theme != nil, func() { opts.Theme = file.Theme })
+	set("keys", file.Keys != nil, func() {
+		opts.Keys = make(map[string][]string, len(file.Keys))
+		for action, keys := range file.Keys {
+			opts.Keys[action] = keys
+		}
+	})
+
+	if file.Thresholds != nil {
+		thresholds, err := counterThresholds(file.Thresholds)
+		if err != nil {
+			return err
+		}
+		opts.Thresholds = thresholds
+		opts.Sources["thresholds"] = file.Path
+	}
+	return opts.normalize()
+}
+
+func counterThresholds(raw map[string]int) (map[jacoco.CounterType]int, error) {
+	thresholds := make(map[jacoco.CounterType]int, len(raw))
+	for name, threshold := range raw {
+		t, ok := lookupCounterType(name)
+		if !ok {
+			names := make([]string, len(thresholdCounterTypes))
+			for i, t := range thresholdCounterTypes {
+				names[i] = strings.ToLower(string(t))
+			}
+			return nil, fmt.Errorf("thresholds には %s を指定してください: %s", strings.Join(names, " / "), name)
+		}
+		thresholds[t] = threshold
+	}
+	return thresholds, nil
+}
+
+func lookupCounterType(name string) (jacoco.CounterType, bool) {
+	for _, t := range thresholdCounterTypes {
+		if strings.EqualFold(string(t), strings.TrimSpace(name)) {
+			return t, true
+		}
+	}
+	return "", false
+}
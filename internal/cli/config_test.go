@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/config"
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
+)
+
+func TestApplyConfigKeepsCommandLineValues(t *testing.T) {
+	opts, err := Parse([]string{"--sort", "name", "report.xml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	threshold, sort, merge := 70, "coverage", "MAX"
+	file := config.File{
+		Path:       "/project/.crv.yaml",
+		Threshold:  &threshold,
+		Thresholds: map[string]int{"Branch": 60},
+		Sort:       &sort,
+		Merge:      &merge,
+		Reports:    []string{"/project/build/jacoco.xml"},
+		Keys:       map[string]config.Keys{"quit": {"x"}},
+	}
+	if err := opts.ApplyConfig(file); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+
+	if opts.Threshold != 70 || opts.Sources["threshold"] != file.Path {
+		t.Fatalf("threshold should come from the file: %d (%s)", opts.Threshold, opts.Sources["threshold"])
+	}
+	if opts.Thresholds[jacoco.CounterBranch] != 60 {
+		t.Fatalf("thresholds mismatch: %v", opts.Thresholds)
+	}
+	if opts.Sort != "name" || opts.Sources["sort"] != SourceFlag {
+		t.Fatalf("flag should override the file: %s (%s)", opts.Sort, opts.Sources["sort"])
+	}
+	if opts.Merge != "max" {
+		t.Fatalf("file values should be normalized: %s", opts.Merge)
+	}
+	if len(opts.Paths) != 1 || opts.Paths[0] != "report.xml" || opts.Sources["reports"] != SourceArgs {
+		t.Fatalf("path arguments should override reports: %v", opts.Paths)
+	}
+	if opts.Keys["quit"][0] != "x" || opts.Sources["format"] != SourceDefault {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+}
+
+func TestApplyConfigRejectsInvalidValues(t *testing.T) {
+	sort := "size"
+	for _, file := range []config.File{
+		{Path: "a", Sort: &sort},
+		{Path: "b", Thresholds: map[string]int{"statement": 80}},
+		{Path: "c", Thresholds: map[string]int{"line": 120}},
+	} {
+		opts, err := Parse(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := opts.ApplyConfig(file); err == nil {
+			t.Fatalf("expected error for %s", file.Path)
+		}
+	}
+}
+
+func TestParseConfigShowCommand(t *testing.T) {
+	opts, err := Parse([]string{"config", "show", "--threshold", "60"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Command != CommandConfigShow || opts.Threshold != 60 {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+	if _, err := Parse([]string{"config", "edit"}); err == nil {
+		t.Fatal("expected error for unknown config subcommand")
+	}
+}
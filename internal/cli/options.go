@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"strings"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
 )
 
 const (
@@ -18,23 +20,114 @@ var validSortKeys = map[string]struct{}{
 	"coverage": {},
 }
 
-// Options is the normalized runtime configuration from CLI arguments.
+var validReportKinds = map[string]struct{}{
+	"auto":               {},
+	"all":                {},
+	"report":             {},
+	"report-integration": {},
+	"report-aggregate":   {},
+}
+
+var validGroupBy = map[string]struct{}{
+	"auto":   {},
+	"none":   {},
+	"input":  {},
+	"format": {},
+	"module": {},
+}
+
+// CommandConfigShow prints the effective configuration instead of starting
+// the viewer.
+const CommandConfigShow = "config show"
+
+// Sources of option values reported by `crv config show`. Values set by the
+// project configuration file are attributed to the file path.
+const (
+	SourceDefault = "default"
+	SourceFlag    = "flag"
+	SourceArgs    = "args"
+)
+
+// Options is the normalized runtime configuration from CLI arguments and the
+// project configuration file.
 type Options struct {
-	Path        string
-	Format      string
-	Threshold   int
-	Sort        string
-	Watch       bool
-	NoColor     bool
-	ShowVersion bool
-	ShowHelp    bool
+	Command        string
+	Paths          []string
+	Format         string
+	GroupBy        string
+	Merge          string
+	ReportKind     string
+	Properties     map[string]string
+	Threshold      int
+	Thresholds     map[jacoco.CounterType]int
+	Sort           string
+	Watch          bool
+	Explain        bool
+	NoColor        bool
+	Fold           bool
+	Tree           bool
+	ShowSynthetic  bool
+	QualifiedTypes bool
+	ShowVersion    bool
+	ShowHelp       bool
+	SourceRoots    []string
+	Exclude        []string
+	Include        []string
+	Theme          map[string]string
+	Keys           map[string][]string
+
+	// ConfigPath is the configuration file that was applied, if any.
+	ConfigPath string
+	// Sources maps configuration keys to where their value came from.
+	Sources map[string]string
+}
+
+// flagKeys maps flag names to the configuration keys they set.
+var flagKeys = map[string]string{
+	"threshold":       "threshold",
+	"t":               "threshold",
+	"format":          "format",
+	"group-by":        "group-by",
+	"merge":           "merge",
+	"report-kind":     "report-kind",
+	"sort":            "sort",
+	"s":               "sort",
+	"watch":           "watch",
+	"no-color":        "no-color",
+	"fold":            "fold",
+	"tree":            "tree",
+	"show-synthetic":  "show-synthetic",
+	"qualified-types": "qualified-types",
+	"exclude":         "exclude",
+	"include":         "include",
+	"source-root":     "source-roots",
+}
+
+// ConfigKeys lists the keys of the configuration file in display order.
+func ConfigKeys() []string {
+	return []string{
+		"threshold", "thresholds", "sort", "format", "group-by", "merge", "report-kind",
+		"watch", "no-color", "fold", "tree", "show-synthetic", "qualified-types", "reports", "source-roots", "exclude", "include", "theme", "keys",
+	}
 }
 
 func Parse(args []string) (Options, error) {
+	command := ""
+	if len(args) > 0 && args[0] == "config" {
+		if len(args) < 2 || args[1] != "show" {
+			return Options{}, errors.New("config のサブコマンドは show です")
+		}
+		command, args = CommandConfigShow, args[2:]
+	}
+
 	opts := Options{
-		Format:    "auto",
-		Threshold: defaultThreshold,
-		Sort:      defaultSort,
+		Command:    command,
+		Format:     "auto",
+		GroupBy:    "auto",
+		Merge:      string(jacoco.MergeUnion),
+		ReportKind: "auto",
+		Threshold:  defaultThreshold,
+		Sort:       defaultSort,
 	}
 
 	fs := flag.NewFlagSet("crv", flag.ContinueOnError)
@@ -44,15 +137,32 @@ func Parse(args []string) (Options, error) {
 	fs.IntVar(&opts.Threshold, "threshold", defaultThreshold, "coverage threshold")
 	fs.IntVar(&opts.Threshold, "t", defaultThreshold, "coverage threshold")
 	fs.StringVar(&opts.Format, "format", "auto", "input format")
+	fs.StringVar(&opts.GroupBy, "group-by", "auto", "top-level grouping of multiple inputs")
+	fs.StringVar(&opts.Merge, "merge", string(jacoco.MergeUnion), "merge strategy for the same class in several inputs")
+	fs.StringVar(&opts.ReportKind, "report-kind", "auto", "kind of detected report to show")
 	fs.StringVar(&opts.Sort, "sort", defaultSort, "initial sort key")
 	fs.StringVar(&opts.Sort, "s", defaultSort, "initial sort key")
 	fs.BoolVar(&opts.Watch, "watch", false, "watch input report and reload automatically")
+	fs.BoolVar(&opts.Explain, "explain", false, "print how reports were detected and exit")
 	fs.BoolVar(&opts.NoColor, "no-color", false, "disable color output")
+	fs.BoolVar(&opts.Fold, "fold", false, "fold nested classes into their top-level class")
+	fs.BoolVar(&opts.Tree, "tree", false, "nest packages by the segments of their names")
+	fs.BoolVar(&opts.ShowSynthetic, "show-synthetic", false, "list synthetic accessor and bridge methods")
+	fs.BoolVar(&opts.QualifiedTypes, "qualified-types", false, "show fully qualified types in method signatures")
+	fs.Var((*stringList)(&opts.Exclude), "exclude", "glob of packages, classes, methods or sources to hide")
+	fs.Var((*stringList)(&opts.Include), "include", "glob of packages, classes, methods or sources to show")
+	fs.Var((*stringList)(&opts.SourceRoots), "source-root", "directory that source files are looked up in")
 	fs.BoolVar(&opts.ShowVersion, "version", false, "show version")
 	fs.BoolVar(&opts.ShowVersion, "v", false, "show version")
 	fs.BoolVar(&opts.ShowHelp, "help", false, "show help")
 	fs.BoolVar(&helpShort, "h", false, "show help")
 
+	args, properties, err := extractProperties(args)
+	if err != nil {
+		return Options{}, err
+	}
+	opts.Properties = properties
+
 	if err := fs.Parse(args); err != nil {
 		return Options{}, err
 	}
@@ -64,44 +174,175 @@ func Parse(args []string) (Options, error) {
 		return opts, nil
 	}
 
-	rest := fs.Args()
-	if len(rest) > 1 {
-		return Options{}, errors.New("path は1つだけ指定できます")
+	opts.Paths = fs.Args()
+	opts.Sources = map[string]string{}
+	for _, key := range ConfigKeys() {
+		opts.Sources[key] = SourceDefault
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if key, ok := flagKeys[f.Name]; ok {
+			opts.Sources[key] = SourceFlag
+		}
+	})
+	if len(opts.Paths) > 0 {
+		opts.Sources["reports"] = SourceArgs
+	}
+	if err := opts.normalize(); err != nil {
+		return Options{}, err
+	}
+	return opts, nil
+}
+
+// normalize validates option values and brings them into canonical form.
+func (opts *Options) normalize() error {
+	stdinCount := 0
+	for _, path := range opts.Paths {
+		if path == "-" {
+			stdinCount++
+		}
 	}
-	if len(rest) == 1 {
-		opts.Path = rest[0]
+	if stdinCount > 1 {
+		return errors.New("標準入力（-）は1回だけ指定できます")
 	}
 
 	if opts.Threshold < 0 || opts.Threshold > 100 {
-		return Options{}, fmt.Errorf("threshold は 0 から 100 の範囲で指定してください: %d", opts.Threshold)
+		return fmt.Errorf("threshold は 0 から 100 の範囲で指定してください: %d", opts.Threshold)
+	}
+
+	for t, threshold := range opts.Thresholds {
+		if threshold < 0 || threshold > 100 {
+			return fmt.Errorf("thresholds.%s は 0 から 100 の範囲で指定してください: %d", strings.ToLower(string(t)), threshold)
+		}
 	}
 
 	opts.Format = strings.ToLower(strings.TrimSpace(opts.Format))
-	switch opts.Format {
-	case "auto", "jacoco", "cobertura", "lcov":
-	default:
-		return Options{}, fmt.Errorf("format は auto / jacoco / cobertura / lcov を指定してください: %s", opts.Format)
+	if opts.Format != string(jacoco.FormatAuto) {
+		if _, ok := jacoco.LookupParser(opts.Format); !ok {
+			jacoco.RegisterPathParsers()
+		}
+		if _, ok := jacoco.LookupParser(opts.Format); !ok {
+			return fmt.Errorf("format は %s を指定してください: %s", strings.Join(formatChoices(), " / "), opts.Format)
+		}
+	}
+
+	opts.GroupBy = strings.ToLower(strings.TrimSpace(opts.GroupBy))
+	if _, ok := validGroupBy[opts.GroupBy]; !ok {
+		return fmt.Errorf("group-by は auto / none / input / format / module を指定してください: %s", opts.GroupBy)
+	}
+
+	opts.Merge = strings.ToLower(strings.TrimSpace(opts.Merge))
+	if !validMergeStrategy(opts.Merge) {
+		return fmt.Errorf("merge は sum / union / max を指定してください: %s", opts.Merge)
+	}
+
+	opts.ReportKind = strings.ToLower(strings.TrimSpace(opts.ReportKind))
+	if _, ok := validReportKinds[opts.ReportKind]; !ok {
+		return fmt.Errorf("report-kind は auto / all / report / report-integration / report-aggregate を指定してください: %s", opts.ReportKind)
 	}
 
 	opts.Sort = strings.ToLower(opts.Sort)
 	if _, ok := validSortKeys[opts.Sort]; !ok {
-		return Options{}, fmt.Errorf("sort は name または coverage を指定してください: %s", opts.Sort)
+		return fmt.Errorf("sort は name または coverage を指定してください: %s", opts.Sort)
 	}
 
-	return opts, nil
+	return nil
+}
+
+// formatChoices lists the registered formats. $PATH plugins are among them
+// only once a run has needed them, so help output never scans $PATH.
+func formatChoices() []string {
+	return append([]string{string(jacoco.FormatAuto)}, jacoco.ParserNames()...)
 }
 
 func Usage() string {
-	return strings.TrimSpace(`Usage:
-  crv [options] [path]
+	return strings.TrimSpace(fmt.Sprintf(`Usage:
+  crv [options] [path...]
+  crv [options] -        標準入力からレポートを読み込む（gzip/zstd/bzip2 圧縮も可）
+  crv config show [options]  設定ファイルとオプションを反映した設定値と、その指定元を表示
+  path にはディレクトリ（配下のレポートを検索）や glob（例: build/**/jacoco.xml）も指定できます
+  カレントディレクトリから上位へ .crv.yaml を探し、オプションの既定値として読み込みます（オプション指定が優先）
 
 Options:
-      --format <fmt>    入力フォーマット（auto|jacoco|cobertura|lcov, default: auto）
+      --format <fmt>    入力フォーマット（%s|PATH 上の crv-format-<name>, default: auto）
+      --group-by <key>  複数入力の最上位グループ（auto|none|input|format|module, default: auto）
+      --merge <mode>    同じクラスを含む入力のマージ方法（sum|union|max, default: union）
+      --report-kind <k> 自動検出するレポート種別（auto|all|report|report-integration|report-aggregate, default: auto）
+  -D<key>=<value>      自動検出で使う Maven プロパティを上書き（複数指定可）
   -t, --threshold <n>  カバレッジ閾値（0-100, default: 80）
   -s, --sort <key>     初期ソート（name|coverage, default: name）
+      --exclude <glob> 除外するパッケージ・クラス・メソッド名またはソースパス（複数指定・カンマ区切り可）
+      --include <glob> 表示するパッケージ・クラス・メソッド名またはソースパス（複数指定・カンマ区切り可）
+      --source-root <dir> ソースファイルのルート。ソース中の除外プラグマを集計に反映（複数指定・カンマ区切り可）
       --watch          レポート変更を監視して自動再読み込み
+      --explain        レポート自動検出の判断過程を表示して終了
       --no-color       カラー出力を無効化
+      --fold           内部クラス・匿名クラスを外側のクラスにまとめて表示
+      --tree           パッケージを名前の区切り（/ または .）でツリー表示
+      --show-synthetic 合成アクセサ・ブリッジメソッドも表示
+      --qualified-types メソッドシグネチャの型をパッケージ名付きで表示
   -v, --version        バージョンを表示
   -h, --help           ヘルプを表示
-`)
+`, strings.Join(formatChoices(), "|")))
+}
+
+// stringList collects repeatable list flags. Each value may hold several
+// comma separated items.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			*l = append(*l, p)
+		}
+	}
+	return nil
+}
+
+func validMergeStrategy(name string) bool {
+	for _, strategy := range jacoco.MergeStrategies() {
+		if string(strategy) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// extractProperties removes Maven style -Dkey=value and -D key=value
+// arguments, which the flag package cannot express, before flags are parsed.
+func extractProperties(args []string) ([]string, map[string]string, error) {
+	var properties map[string]string
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-D") {
+			rest = append(rest, arg)
+			continue
+		}
+		def := strings.TrimPrefix(arg, "-D")
+		if def == "" {
+			if i+1 >= len(args) {
+				return nil, nil, errors.New("-D には key=value を指定してください")
+			}
+			i++
+			def = args[i]
+		}
+		key, value, _ := strings.Cut(def, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, nil, fmt.Errorf("-D には key=value を指定してください: %s", def)
+		}
+		if properties == nil {
+			properties = map[string]string{}
+		}
+		properties[key] = value
+	}
+	return rest, properties, nil
 }
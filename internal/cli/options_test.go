@@ -1,14 +1,17 @@
 package cli
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestParseDefaults(t *testing.T) {
 	opts, err := Parse([]string{"report.xml"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if opts.Path != "report.xml" {
-		t.Fatalf("path mismatch: %s", opts.Path)
+	if len(opts.Paths) != 1 || opts.Paths[0] != "report.xml" {
+		t.Fatalf("path mismatch: %v", opts.Paths)
 	}
 	if opts.Format != "auto" {
 		t.Fatalf("format mismatch: %s", opts.Format)
@@ -71,3 +74,116 @@ func TestParseVersionSkipsValidation(t *testing.T) {
 		t.Fatal("version flag should be true")
 	}
 }
+
+func TestUsageListsRegisteredFormats(t *testing.T) {
+	if !strings.Contains(Usage(), "auto|jacoco|cobertura|lcov") {
+		t.Fatalf("usage should list registered formats: %q", Usage())
+	}
+}
+
+func TestParseMultiplePathsAndGroupBy(t *testing.T) {
+	opts, err := Parse([]string{"--group-by", "input", "jacoco.xml", "lcov.info"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(opts.Paths) != 2 || opts.Paths[1] != "lcov.info" {
+		t.Fatalf("paths mismatch: %v", opts.Paths)
+	}
+	if opts.GroupBy != "input" {
+		t.Fatalf("group-by mismatch: %s", opts.GroupBy)
+	}
+}
+
+func TestParseRejectsInvalidGroupBy(t *testing.T) {
+	_, err := Parse([]string{"--group-by", "planet", "report.xml"})
+	if err == nil {
+		t.Fatal("expected group-by error")
+	}
+}
+
+func TestParseRejectsRepeatedStdin(t *testing.T) {
+	_, err := Parse([]string{"-", "-"})
+	if err == nil {
+		t.Fatal("expected error for repeated stdin")
+	}
+}
+
+func TestParseMergeStrategy(t *testing.T) {
+	opts, err := Parse([]string{"report.xml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Merge != "union" {
+		t.Fatalf("default merge mismatch: %s", opts.Merge)
+	}
+	opts, err = Parse([]string{"--merge", "MAX", "report.xml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Merge != "max" {
+		t.Fatalf("merge mismatch: %s", opts.Merge)
+	}
+	if _, err := Parse([]string{"--merge", "avg", "report.xml"}); err == nil {
+		t.Fatal("expected merge error")
+	}
+}
+
+func TestParseMavenProperties(t *testing.T) {
+	opts, err := Parse([]string{"-Dcoverage.dir=out", "--watch", "-D", "flag", "report.xml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Properties["coverage.dir"] != "out" || opts.Properties["flag"] != "" {
+		t.Fatalf("properties mismatch: %v", opts.Properties)
+	}
+	if _, ok := opts.Properties["flag"]; !ok {
+		t.Fatalf("property without value should be kept: %v", opts.Properties)
+	}
+	if !opts.Watch || len(opts.Paths) != 1 || opts.Paths[0] != "report.xml" {
+		t.Fatalf("remaining args mismatch: %+v", opts)
+	}
+
+	if _, err := Parse([]string{"-D=value"}); err == nil {
+		t.Fatal("expected error for empty property key")
+	}
+}
+
+func TestParseReportKind(t *testing.T) {
+	opts, err := Parse([]string{"--report-kind", "Report-Integration"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.ReportKind != "report-integration" {
+		t.Fatalf("report kind mismatch: %s", opts.ReportKind)
+	}
+	if _, err := Parse([]string{"--report-kind", "site"}); err == nil {
+		t.Fatal("expected report-kind error")
+	}
+}
+
+func TestParseExcludeAndInclude(t *testing.T) {
+	opts, err := Parse([]string{"--exclude", "*_Generated, **.proto.**", "--exclude=**.equals", "--include", "com.example.**"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"*_Generated", "**.proto.**", "**.equals"}
+	if strings.Join(opts.Exclude, "|") != strings.Join(want, "|") {
+		t.Fatalf("exclude mismatch: %v", opts.Exclude)
+	}
+	if len(opts.Include) != 1 || opts.Include[0] != "com.example.**" {
+		t.Fatalf("include mismatch: %v", opts.Include)
+	}
+	if opts.Sources["exclude"] != SourceFlag || opts.Sources["include"] != SourceFlag {
+		t.Fatalf("sources mismatch: %v", opts.Sources)
+	}
+}
+
+func TestParseSourceRoots(t *testing.T) {
+	opts, err := Parse([]string{"--source-root", "src/main/java,src/test/java", "--source-root", "gen"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(opts.SourceRoots, "|") != "src/main/java|src/test/java|gen" || opts.Sources["source-roots"] != SourceFlag {
+		t.Fatalf("source roots mismatch: %v (%s)", opts.SourceRoots, opts.Sources["source-roots"])
+	}
+}
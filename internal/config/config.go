@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the project configuration files looked up from the working
+// directory upward, in order of preference within a directory.
+var FileNames = []string{".crv.yaml", ".crv.yml"}
+
+// File is the content of a project configuration file. Pointer fields are
+// nil when the file does not set them, so that defaults stay distinguishable
+// from explicit values.
+type File struct {
+	Threshold      *int              `yaml:"threshold"`
+	Thresholds     map[string]int    `yaml:"thresholds"`
+	Sort           *string           `yaml:"sort"`
+	Format         *string           `yaml:"format"`
+	GroupBy        *string           `yaml:"group-by"`
+	Merge          *string           `yaml:"merge"`
+	ReportKind     *string           `yaml:"report-kind"`
+	Watch          *bool             `yaml:"watch"`
+	NoColor        *bool             `yaml:"no-color"`
+	Fold           *bool             `yaml:"fold"`
+	Tree           *bool             `yaml:"tree"`
+	ShowSynthetic  *bool             `yaml:"show-synthetic"`
+	QualifiedTypes *bool             `yaml:"qualified-types"`
+	Reports        []string          `yaml:"reports"`
+	SourceRoots    []string          `yaml:"source-roots"`
+	Exclude        []string          `yaml:"exclude"`
+	Include        []string          `yaml:"include"`
+	Theme          map[string]string `yaml:"theme"`
+	Keys           map[string]Keys   `yaml:"keys"`
+
+	// Path is the file the configuration was read from.
+	Path string `yaml:"-"`
+}
+
+// Keys is a key binding: a single key or a list of keys.
+type Keys []string
+
+func (k *Keys) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*k = Keys{node.Value}
+		return nil
+	}
+	var keys []string
+	if err := node.Decode(&keys); err != nil {
+		return err
+	}
+	*k = keys
+	return nil
+}
+
+// Find returns the closest configuration file in dir or one of its parents.
+func Find(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		for _, name := range FileNames {
+			path := filepath.Join(abs, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, true
+			}
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", false
+		}
+		abs = parent
+	}
+}
+
+// Load reads a configuration file. Unknown keys are rejected so that typos
+// do not go unnoticed. Relative report paths and source roots are resolved
+// against the directory of the file.
+func Load(path string) (File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+	var file File
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return File{}, fmt.Errorf("%s: %w", path, err)
+	}
+	file.Path = path
+	dir := filepath.Dir(path)
+	file.Reports = resolvePaths(dir, file.Reports)
+	file.SourceRoots = resolvePaths(dir, file.SourceRoots)
+	return file, nil
+}
+
+// Discover finds and loads the configuration file for dir. It returns a zero
+// File when there is none.
+func Discover(dir string) (File, error) {
+	path, ok := Find(dir)
+	if !ok {
+		return File{}, nil
+	}
+	return Load(path)
+}
+
+func resolvePaths(dir string, paths []string) []string {
+	if paths == nil {
+		return nil
+	}
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		if filepath.IsAbs(p) || p == "-" {
+			out[i] = p
+			continue
+		}
+		out[i] = filepath.Join(dir, filepath.FromSlash(p))
+	}
+	return out
+}
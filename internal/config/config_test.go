@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverSearchesParentDirectories(t *testing.T) {
+	root := t.TempDir()
+	writeConfig(t, filepath.Join(root, ".crv.yml"), `
+threshold: 75
+thresholds:
+  branch: 60
+sort: coverage
+reports:
+  - build/jacoco.xml
+  - /abs/lcov.info
+source-roots: [src/main/java]
+keys:
+  quit: x
+  down: [n, down]
+theme:
+  high: "#00FF00"
+`)
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+
+	file, err := Discover(nested)
+	if err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+	if file.Path != filepath.Join(root, ".crv.yml") {
+		t.Fatalf("path mismatch: %s", file.Path)
+	}
+	if file.Threshold == nil || *file.Threshold != 75 || file.Thresholds["branch"] != 60 {
+		t.Fatalf("thresholds mismatch: %+v", file)
+	}
+	if file.Sort == nil || *file.Sort != "coverage" || file.Format != nil {
+		t.Fatalf("unset values should stay nil: %+v", file)
+	}
+	wantReports := []string{filepath.Join(root, "build/jacoco.xml"), "/abs/lcov.info"}
+	if !reflect.DeepEqual(file.Reports, wantReports) {
+		t.Fatalf("reports mismatch: %v", file.Reports)
+	}
+	if !reflect.DeepEqual(file.SourceRoots, []string{filepath.Join(root, "src/main/java")}) {
+		t.Fatalf("source roots mismatch: %v", file.SourceRoots)
+	}
+	if !reflect.DeepEqual(file.Keys["quit"], Keys{"x"}) || !reflect.DeepEqual(file.Keys["down"], Keys{"n", "down"}) {
+		t.Fatalf("keys mismatch: %v", file.Keys)
+	}
+	if file.Theme["high"] != "#00FF00" {
+		t.Fatalf("theme mismatch: %v", file.Theme)
+	}
+}
+
+func TestDiscoverWithoutFile(t *testing.T) {
+	file, err := Discover(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file.Path != "" {
+		t.Fatalf("expected no config file, got %s", file.Path)
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".crv.yaml")
+	writeConfig(t, path, "treshold: 80\n")
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+}
+
+func TestLoadAcceptsEmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".crv.yaml")
+	writeConfig(t, path, "")
+	file, err := Load(path)
+	if err != nil || file.Path != path {
+		t.Fatalf("unexpected result: %+v (%v)", file, err)
+	}
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+}
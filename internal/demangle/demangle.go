@@ -0,0 +1,60 @@
+// Package demangle turns the mangled symbol names that C++, Rust and Swift
+// compilers emit, and that LCOV and gcov reports carry as function names,
+// back into source-level names.
+//
+// C++ (Itanium ABI) and Rust symbols are handled by
+// github.com/ianlancetaylor/demangle. The Swift demangler covers the
+// constructs that appear in function symbols of everyday code. Anything that
+// is not understood is reported as not demangled rather than guessed at.
+package demangle
+
+import (
+	"strings"
+
+	demangler "github.com/ianlancetaylor/demangle"
+)
+
+// maxRepeat bounds the repeat counts of Swift standard types and
+// substitutions, such as the 2 in S2i, so corrupt input cannot push unbounded
+// nodes.
+const maxRepeat = 256
+
+// maxLength bounds demangled C++ and Rust names to 2^16 characters, since
+// substitutions can make them grow exponentially.
+const maxLength = 16
+
+// Demangle returns the readable form of a mangled symbol and true, or the
+// name unchanged and false when it is not a symbol this package understands.
+// Corrupt symbols never panic; they are returned unchanged.
+func Demangle(name string) (out string, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			out, ok = name, false
+		}
+	}()
+	symbol := strings.TrimSpace(name)
+	if strings.HasPrefix(symbol, "__Z") || strings.HasPrefix(symbol, "__R") {
+		// Mach-O prefixes C symbols with an extra underscore.
+		symbol = symbol[1:]
+	}
+	switch {
+	case strings.HasPrefix(symbol, "_Z") || strings.HasPrefix(symbol, "_R"):
+		s, err := demangler.ToString(symbol, demangler.MaxLength(maxLength))
+		out, ok = s, err == nil && s != ""
+	case isSwiftSymbol(symbol):
+		out, ok = demangleSwift(symbol)
+	}
+	if !ok {
+		return name, false
+	}
+	return out, true
+}
+
+// splitSuffix separates a vendor suffix such as .cold or .llvm.1234 that the
+// compiler appends to a clone of a function.
+func splitSuffix(s string) (symbol, suffix string) {
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return s[:i], s[i:]
+	}
+	return s, ""
+}
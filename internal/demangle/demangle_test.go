@@ -0,0 +1,64 @@
+package demangle
+
+import "testing"
+
+func TestDemangle(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"_Z3addii", "add(int, int)"},
+		{"_ZN3foo3barEv", "foo::bar()"},
+		{"_ZN3FooC2Ev", "Foo::Foo()"},
+		{"_ZNK3Foo4sizeEv", "Foo::size() const"},
+		{"_ZNSt6vectorIiSaIiEE9push_backERKi", "std::vector<int, std::allocator<int> >::push_back(int const&)"},
+		{"_Z3maxIiET_S0_S0_", "int max<int>(int, int)"},
+		{"_Z4workPFviE", "work(void (*)(int))"},
+		{"_Z3addii.cold", "add(int, int) [clone .cold]"},
+		{"__ZN3foo3barEv", "foo::bar()"},
+		{"_ZN7mycrate3foo17h0123456789abcdefE", "mycrate::foo"},
+		{"_ZN4core3ptr42drop_in_place$LT$alloc..string..String$GT$17h0123456789abcdefE", "core::ptr::drop_in_place<alloc::string::String>"},
+		{"_RNvCs1234_7mycrate3foo", "mycrate::foo"},
+		{"_RINvCs1234_7mycrate3fooReEB2_", "mycrate::foo::<&str>"},
+		{"_RNCNvCs1234_7mycrate4main0B3_", "mycrate::main::{closure#0}"},
+		{"$s4main3FooV3baryyF", "main.Foo.bar()"},
+		{"$s4main3foo1xySi_tF", "main.foo(x:)"},
+		{"$s4main3FooVACycfC", "main.Foo.init()"},
+		{"$s4main3FooV5countSivg", "main.Foo.count.getter"},
+		{"$s4main3fooyyFyycfU_", "closure #1 in main.foo()"},
+		{"$sSa4mainE3fooyyF", "Swift.Array.foo()"},
+	}
+	for _, tt := range tests {
+		got, ok := Demangle(tt.name)
+		if !ok || got != tt.want {
+			t.Errorf("Demangle(%q) = %q, %v, want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestDemangleLeavesOtherNamesAlone(t *testing.T) {
+	for _, name := range []string{"main", "com.example.Foo", "_Z", "_ZN3foo3bar", "_RNv", "$s4main", "_Z999foo", "_ZS2000000000000_", "_Z1fS2000000000000_", "_RNvB99999999999999999999_3foo", "$s4main3FooVAZ99999999999999999999_"} {
+		if got, ok := Demangle(name); ok || got != name {
+			t.Fatalf("Demangle(%q) = %q, %v", name, got, ok)
+		}
+	}
+}
+
+func FuzzDemangle(f *testing.F) {
+	for _, seed := range []string{
+		"_ZNSt6vectorIiSaIiEE9push_backERKi", "_Z3maxIiET_S0_S0_", "_ZS2000000000000_",
+		"_ZN4core3ptr42drop_in_place$LT$alloc..string..String$GT$17h0123456789abcdefE",
+		"_RINvCs1234_7mycrate3fooReEB2_", "$s4main3foo1xySi_tF", "$sSa4mainE3fooyyF", "$s4main3fooyyFyycfU_",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		got, ok := Demangle(name)
+		if !ok && got != name {
+			t.Fatalf("Demangle(%q) changed a name it did not demangle: %q", name, got)
+		}
+		if ok && got == "" {
+			t.Fatalf("Demangle(%q) returned an empty name", name)
+		}
+	})
+}
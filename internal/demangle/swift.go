@@ -0,0 +1,583 @@
+package demangle
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Swift mangling is a postfix language: operators pop the nodes that earlier
+// ones pushed. Only the entity name is rendered, in the style of
+// swift-demangle --simplified (main.Foo.bar(x:)), so types are tracked just
+// far enough to find the parameter count that argument labels depend on.
+
+var errSwift = errors.New("unsupported swift symbol")
+
+var swiftPrefixes = []string{"_$s", "$s", "_$S", "$S", "_$e", "$e", "_T0"}
+
+var swiftAccessors = map[byte]string{
+	'p': "",
+	'g': "getter",
+	'G': "getter",
+	's': "setter",
+	'M': "modify",
+	'r': "read",
+	'w': "willset",
+	'W': "didset",
+	'm': "materializeForSet",
+}
+
+func isSwiftSymbol(s string) bool {
+	for _, prefix := range swiftPrefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type swiftKind int
+
+const (
+	swiftIdent swiftKind = iota
+	swiftType
+	swiftNominal
+	swiftEntity
+	swiftEmptyList
+	swiftMarker
+	swiftVariadic
+	swiftSignature
+)
+
+type swiftNode struct {
+	kind swiftKind
+	text string
+	// params is the number of parameters of a function type, or the number
+	// of elements of a tuple type.
+	params int
+	tuple  bool
+}
+
+type swiftParser struct {
+	s     string
+	pos   int
+	stack []swiftNode
+	subs  []swiftNode
+	words []string
+}
+
+func demangleSwift(s string) (out string, ok bool) {
+	symbol, _ := splitSuffix(s)
+	for _, prefix := range swiftPrefixes {
+		if strings.HasPrefix(symbol, prefix) {
+			symbol = symbol[len(prefix):]
+			break
+		}
+	}
+	p := &swiftParser{s: symbol}
+	defer func() {
+		if r := recover(); r != nil {
+			out, ok = "", false
+		}
+	}()
+	for p.pos < len(p.s) {
+		p.operator()
+	}
+	if len(p.stack) != 1 || p.stack[0].kind != swiftEntity {
+		return "", false
+	}
+	return p.stack[0].text, true
+}
+
+func (p *swiftParser) fail() {
+	panic(errSwift)
+}
+
+func (p *swiftParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *swiftParser) next() byte {
+	if p.pos >= len(p.s) {
+		p.fail()
+	}
+	c := p.s[p.pos]
+	p.pos++
+	return c
+}
+
+func (p *swiftParser) push(n swiftNode) {
+	p.stack = append(p.stack, n)
+}
+
+func (p *swiftParser) pop(kinds ...swiftKind) (swiftNode, bool) {
+	if len(p.stack) == 0 {
+		return swiftNode{}, false
+	}
+	top := p.stack[len(p.stack)-1]
+	for _, k := range kinds {
+		if top.kind == k {
+			p.stack = p.stack[:len(p.stack)-1]
+			return top, true
+		}
+	}
+	return swiftNode{}, false
+}
+
+func (p *swiftParser) mustPop(kinds ...swiftKind) swiftNode {
+	n, ok := p.pop(kinds...)
+	if !ok {
+		p.fail()
+	}
+	return n
+}
+
+func (p *swiftParser) popType() swiftNode {
+	return p.mustPop(swiftType, swiftNominal)
+}
+
+// popContext pops the declaration context of an entity. A bare identifier is
+// a module name.
+func (p *swiftParser) popContext() string {
+	return p.mustPop(swiftIdent, swiftNominal, swiftEntity).text
+}
+
+func (p *swiftParser) natural() int {
+	start := p.pos
+	for c := p.peek(); c >= '0' && c <= '9'; c = p.peek() {
+		p.pos++
+	}
+	n, err := strconv.Atoi(p.s[start:p.pos])
+	if err != nil {
+		p.fail()
+	}
+	return n
+}
+
+// index reads "_" for 0 or a number followed by "_" for the number plus one.
+func (p *swiftParser) index() int {
+	if p.peek() == '_' {
+		p.pos++
+		return 0
+	}
+	n := p.natural()
+	if p.next() != '_' {
+		p.fail()
+	}
+	return n + 1
+}
+
+func (p *swiftParser) operator() {
+	c := p.next()
+	switch {
+	case c >= '0' && c <= '9':
+		p.pos--
+		p.identifier()
+	case c == 'y':
+		p.push(swiftNode{kind: swiftEmptyList})
+	case c == '_':
+		p.push(swiftNode{kind: swiftMarker})
+	case c == 'd':
+		p.push(swiftNode{kind: swiftVariadic})
+	case c == 't':
+		p.tuple()
+	case c == 'C' || c == 'V' || c == 'O' || c == 'P' || c == 'a':
+		name := p.mustPop(swiftIdent)
+		n := swiftNode{kind: swiftNominal, text: p.popContext() + "." + name.text}
+		p.subs = append(p.subs, n)
+		p.push(n)
+	case c == 'G':
+		p.boundGeneric()
+	case c == 'S':
+		p.standardSubstitution()
+	case c == 'A':
+		p.substitution()
+	case c == 'c':
+		p.push(p.functionType())
+	case c == 'X':
+		if p.next() != 'E' {
+			p.fail()
+		}
+		p.push(p.functionType())
+	case c == 'z' || c == 'n' || c == 'h' || c == 'm':
+		// inout, __owned, __shared and metatypes wrap a type.
+		p.push(swiftNode{kind: swiftType, text: p.popType().text})
+	case c == 'x':
+		p.push(swiftNode{kind: swiftType, text: "A"})
+	case c == 'q':
+		p.index()
+		p.push(swiftNode{kind: swiftType, text: "A"})
+	case c == 'l':
+		p.push(swiftNode{kind: swiftSignature})
+	case c == 'K':
+		// throws
+	case c == 'Y':
+		if p.next() != 'a' {
+			p.fail()
+		}
+	case c == 'L':
+		p.localName()
+	case c == 'E':
+		p.extension()
+	case c == 'F':
+		p.function()
+	case c == 'f':
+		p.functionEntity()
+	case c == 'v':
+		p.variable()
+	case c == 'i':
+		p.subscript()
+	case c == 'Z':
+		entity := p.mustPop(swiftEntity)
+		entity.text = "static " + entity.text
+		p.push(entity)
+	default:
+		p.fail()
+	}
+}
+
+func (p *swiftParser) identifier() {
+	wordSubsts := false
+	if p.peek() == '0' {
+		p.pos++
+		if p.peek() == '0' {
+			// Punycode identifiers are not decoded.
+			p.fail()
+		}
+		wordSubsts = true
+	}
+	var b strings.Builder
+	for {
+		for wordSubsts && isLetter(p.peek()) {
+			c := p.next()
+			ix := int(c - 'a')
+			if isUpper(c) {
+				ix = int(c - 'A')
+				wordSubsts = false
+			}
+			if ix >= len(p.words) {
+				p.fail()
+			}
+			b.WriteString(p.words[ix])
+		}
+		if p.peek() == '0' {
+			p.pos++
+			break
+		}
+		n := p.natural()
+		if n <= 0 || p.pos+n > len(p.s) {
+			p.fail()
+		}
+		slice := p.s[p.pos : p.pos+n]
+		p.pos += n
+		b.WriteString(slice)
+		p.addWords(slice)
+		if !wordSubsts {
+			break
+		}
+	}
+	n := swiftNode{kind: swiftIdent, text: b.String()}
+	p.subs = append(p.subs, n)
+	p.push(n)
+}
+
+// addWords records the words of an identifier for later word substitutions.
+// Words start at a letter and end before an underscore or at a lower to
+// upper case change.
+func (p *swiftParser) addWords(s string) {
+	start := -1
+	for i := 0; i <= len(s); i++ {
+		var c byte
+		if i < len(s) {
+			c = s[i]
+		}
+		if start >= 0 && (c == 0 || c == '_' || (isUpper(c) && !isUpper(s[i-1]))) {
+			if i-start >= 2 && len(p.words) < 26 {
+				p.words = append(p.words, s[start:i])
+			}
+			start = -1
+		}
+		if start < 0 && c != 0 && c != '_' && (c < '0' || c > '9') {
+			start = i
+		}
+	}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || isUpper(c)
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func (p *swiftParser) tuple() {
+	if _, ok := p.pop(swiftEmptyList); ok {
+		p.push(swiftNode{kind: swiftType, text: "()", tuple: true})
+		return
+	}
+	n := 0
+	for {
+		_, first := p.pop(swiftMarker)
+		p.pop(swiftVariadic)
+		p.pop(swiftIdent)
+		p.popType()
+		n++
+		if first {
+			break
+		}
+	}
+	p.push(swiftNode{kind: swiftType, text: "(...)", tuple: true, params: n})
+}
+
+func (p *swiftParser) boundGeneric() {
+	for {
+		for {
+			if _, ok := p.pop(swiftType, swiftNominal); !ok {
+				break
+			}
+		}
+		if _, ok := p.pop(swiftEmptyList); ok {
+			break
+		}
+		p.mustPop(swiftMarker)
+	}
+	n := swiftNode{kind: swiftType, text: p.popType().text}
+	p.subs = append(p.subs, n)
+	p.push(n)
+}
+
+func (p *swiftParser) standardSubstitution() {
+	c := p.next()
+	switch {
+	case c == 'o':
+		p.push(swiftNode{kind: swiftIdent, text: "__C"})
+	case c == 'C':
+		p.push(swiftNode{kind: swiftIdent, text: "__C_Synthesized"})
+	case c == 'g':
+		// Optional sugar: T?
+		p.push(swiftNode{kind: swiftType, text: p.popType().text + "?"})
+	case c == 'c':
+		if !isUpper(p.next()) {
+			p.fail()
+		}
+		p.push(swiftNode{kind: swiftNominal, text: "Swift"})
+	default:
+		// Standard library types, optionally repeated: S2i is Int, Int.
+		p.pos--
+		repeat := 1
+		if c >= '0' && c <= '9' {
+			repeat = p.natural()
+		}
+		name, ok := swiftStandardTypes[p.next()]
+		if !ok || repeat < 1 || repeat > maxRepeat {
+			p.fail()
+		}
+		for i := 0; i < repeat; i++ {
+			p.push(swiftNode{kind: swiftNominal, text: "Swift." + name})
+		}
+	}
+}
+
+// swiftStandardTypes are the one-letter S substitutions for standard library
+// types.
+var swiftStandardTypes = map[byte]string{
+	'A': "AutoreleasingUnsafeMutablePointer",
+	'a': "Array",
+	'B': "BinaryFloatingPoint",
+	'b': "Bool",
+	'D': "Dictionary",
+	'd': "Double",
+	'E': "Encodable",
+	'e': "Decodable",
+	'F': "FloatingPoint",
+	'f': "Float",
+	'G': "RandomNumberGenerator",
+	'H': "Hashable",
+	'h': "Set",
+	'I': "DefaultIndices",
+	'i': "Int",
+	'J': "Character",
+	'j': "Numeric",
+	'K': "BidirectionalCollection",
+	'k': "RandomAccessCollection",
+	'L': "Comparable",
+	'l': "Collection",
+	'M': "MutableCollection",
+	'm': "RangeReplaceableCollection",
+	'N': "ClosedRange",
+	'n': "Range",
+	'O': "ObjectIdentifier",
+	'P': "UnsafePointer",
+	'p': "UnsafeMutablePointer",
+	'Q': "Equatable",
+	'q': "Optional",
+	'R': "UnsafeBufferPointer",
+	'r': "UnsafeMutableBufferPointer",
+	'S': "String",
+	's': "Substring",
+	'T': "Sequence",
+	't': "IteratorProtocol",
+	'U': "UnsignedInteger",
+	'u': "UInt",
+	'V': "UnsafeRawPointer",
+	'v': "UnsafeMutableRawPointer",
+	'W': "UnsafeRawBufferPointer",
+	'w': "UnsafeMutableRawBufferPointer",
+	'X': "RangeExpression",
+	'x': "Strideable",
+	'Y': "RawRepresentable",
+	'y': "StringProtocol",
+	'Z': "SignedInteger",
+	'z': "BinaryInteger",
+}
+
+func (p *swiftParser) substitution() {
+	repeat := -1
+	for {
+		c := p.next()
+		switch {
+		case c >= 'a' && c <= 'z':
+			p.pushSubstitution(repeat, int(c-'a'))
+			repeat = -1
+		case isUpper(c):
+			p.pushSubstitution(repeat, int(c-'A'))
+			return
+		case c == '_':
+			p.pushSubstitution(-1, repeat+27)
+			return
+		default:
+			p.pos--
+			repeat = p.natural()
+		}
+	}
+}
+
+func (p *swiftParser) pushSubstitution(repeat, ix int) {
+	if ix < 0 || ix >= len(p.subs) {
+		p.fail()
+	}
+	if repeat < 1 {
+		repeat = 1
+	}
+	if repeat > maxRepeat {
+		p.fail()
+	}
+	for i := 0; i < repeat; i++ {
+		p.push(p.subs[ix])
+	}
+}
+
+// params pops a parameter or result type. An empty list is the empty tuple.
+func (p *swiftParser) params() swiftNode {
+	if _, ok := p.pop(swiftEmptyList); ok {
+		return swiftNode{kind: swiftType, text: "()", tuple: true}
+	}
+	n := p.popType()
+	if !n.tuple {
+		n.params = 1
+	}
+	return n
+}
+
+func (p *swiftParser) functionType() swiftNode {
+	params := p.params()
+	p.params()
+	return swiftNode{kind: swiftType, text: "function", params: params.params}
+}
+
+// labels pops the argument labels of a declaration with n parameters and
+// renders them as (x:_:).
+func (p *swiftParser) labels(n int) string {
+	if _, ok := p.pop(swiftEmptyList); ok {
+		return "(" + strings.Repeat("_:", n) + ")"
+	}
+	labels := make([]string, n)
+	for i := n - 1; i >= 0; i-- {
+		if label, ok := p.pop(swiftIdent); ok {
+			labels[i] = label.text + ":"
+			continue
+		}
+		p.mustPop(swiftMarker)
+		labels[i] = "_:"
+	}
+	return "(" + strings.Join(labels, "") + ")"
+}
+
+func (p *swiftParser) function() {
+	p.pop(swiftSignature)
+	fn := p.functionType()
+	labels := p.labels(fn.params)
+	name := p.mustPop(swiftIdent)
+	p.pushEntity(p.popContext() + "." + name.text + labels)
+}
+
+func (p *swiftParser) functionEntity() {
+	switch c := p.next(); c {
+	case 'C', 'c':
+		p.pop(swiftSignature)
+		fn := p.popType()
+		labels := p.labels(fn.params)
+		p.pushEntity(p.popContext() + ".init" + labels)
+	case 'D', 'd':
+		p.pushEntity(p.popContext() + ".deinit")
+	case 'U', 'u':
+		ix := p.index()
+		p.popType()
+		kind := "closure"
+		if c == 'u' {
+			kind = "implicit closure"
+		}
+		p.pushEntity(kind + " #" + strconv.Itoa(ix+1) + " in " + p.popContext())
+	default:
+		p.fail()
+	}
+}
+
+func (p *swiftParser) variable() {
+	p.popType()
+	name := p.mustPop(swiftIdent)
+	p.pushEntity(p.accessor(p.popContext() + "." + name.text))
+}
+
+func (p *swiftParser) subscript() {
+	p.pop(swiftSignature)
+	fn := p.popType()
+	labels := p.labels(fn.params)
+	p.pushEntity(p.accessor(p.popContext() + ".subscript" + labels))
+}
+
+func (p *swiftParser) accessor(entity string) string {
+	name, ok := swiftAccessors[p.next()]
+	if !ok {
+		p.fail()
+	}
+	if name == "" {
+		return entity
+	}
+	return entity + "." + name
+}
+
+// localName handles the LL suffix of private declarations and the L<index>
+// suffix of local ones, neither of which is shown.
+func (p *swiftParser) localName() {
+	if p.peek() == 'L' {
+		p.pos++
+		p.mustPop(swiftIdent)
+		return
+	}
+	p.index()
+}
+
+func (p *swiftParser) extension() {
+	p.pop(swiftSignature)
+	p.mustPop(swiftIdent)
+	p.push(swiftNode{kind: swiftNominal, text: p.popType().text})
+}
+
+func (p *swiftParser) pushEntity(text string) {
+	p.push(swiftNode{kind: swiftEntity, text: text})
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"os"
 	"regexp"
 	"strconv"
 )
@@ -12,7 +11,7 @@ import (
 var coberturaConditionRe = regexp.MustCompile(`\((\d+)/(\d+)\)`)
 
 func ParseCoberturaFile(path string) (Report, error) {
-	f, err := os.Open(path)
+	f, err := OpenInput(path)
 	if err != nil {
 		return Report{}, fmt.Errorf("open cobertura report: %w", err)
 	}
@@ -20,49 +19,181 @@ func ParseCoberturaFile(path string) (Report, error) {
 	return ParseCobertura(f)
 }
 
+// coberturaLine is one <line> entry of a Cobertura class or method.
+type coberturaLine struct {
+	Number            int
+	Hits              int
+	Branch            bool
+	ConditionCoverage string
+}
+
+// ParseCobertura reads a Cobertura XML report, streaming the document and
+// building the model directly.
 func ParseCobertura(r io.Reader) (Report, error) {
-	var xc xmlCoberturaCoverage
-	dec := xml.NewDecoder(r)
-	if err := dec.Decode(&xc); err != nil {
+	p := coberturaParser{s: newXMLStream(r)}
+	report, err := p.parseCoverage()
+	if err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+type coberturaParser struct {
+	s *xmlStream
+}
+
+func (p *coberturaParser) parseCoverage() (Report, error) {
+	if _, err := p.s.root(); err != nil {
 		return Report{}, fmt.Errorf("decode cobertura xml: %w", err)
 	}
 
 	report := Report{Name: "cobertura"}
-	for _, xp := range xc.Packages {
-		pkg := Package{Name: xp.Name}
-		for _, xclass := range xp.Classes {
-			class := Class{Name: xclass.Name, SourceFileName: xclass.File}
-
-			for _, xm := range xclass.Methods {
-				lineCounter, branchCounter := countersFromCoberturaLines(xm.Lines)
-				method := Method{
-					Name:     xm.Name,
-					Desc:     xm.Signature,
-					Line:     firstLineNumber(xm.Lines),
-					Counters: normalizeCoberturaCounters(lineCounter, branchCounter),
-				}
-				class.Methods = append(class.Methods, method)
+	err := p.eachChild("packages", func(start xml.StartElement) error {
+		return p.eachChild("package", func(pkgStart xml.StartElement) error {
+			pkg, err := p.parsePackage(pkgStart)
+			if err != nil {
+				return err
 			}
+			report.Packages = append(report.Packages, pkg)
+			return nil
+		})
+	})
+	if err != nil {
+		return Report{}, fmt.Errorf("decode cobertura xml: %w", err)
+	}
 
-			if len(class.Methods) > 0 {
-				class.Counters = sumMethodCounters(class.Methods)
-			} else {
-				lineCounter, branchCounter := countersFromCoberturaLines(xclass.Lines)
-				class.Counters = normalizeCoberturaCounters(lineCounter, branchCounter)
+	report.Counters = sumPackageCounters(report.Packages)
+	return report, nil
+}
+
+// eachChild calls fn for every child element of the current element named
+// name and skips the others. fn must consume the element it is given.
+func (p *coberturaParser) eachChild(name string, fn func(xml.StartElement) error) error {
+	for {
+		start, ok, err := p.s.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if start.Name.Local != name {
+			if err := p.s.skip(); err != nil {
+				return err
 			}
+			continue
+		}
+		if err := fn(start); err != nil {
+			return err
+		}
+	}
+}
 
+func (p *coberturaParser) parsePackage(start xml.StartElement) (Package, error) {
+	pkg := Package{Name: p.s.internAttr(start, "name")}
+	err := p.eachChild("classes", func(xml.StartElement) error {
+		return p.eachChild("class", func(classStart xml.StartElement) error {
+			class, err := p.parseClass(classStart)
+			if err != nil {
+				return err
+			}
 			pkg.Classes = append(pkg.Classes, class)
+			return nil
+		})
+	})
+	if err != nil {
+		return Package{}, err
+	}
+	pkg.Counters = sumClassCounters(pkg.Classes)
+	return pkg, nil
+}
+
+func (p *coberturaParser) parseClass(start xml.StartElement) (Class, error) {
+	class := Class{
+		Name:           p.s.attr(start, "name"),
+		SourceFileName: p.s.internAttr(start, "filename"),
+	}
+	var classLines []coberturaLine
+	for {
+		child, ok, err := p.s.next()
+		if err != nil {
+			return Class{}, err
+		}
+		if !ok {
+			break
+		}
+		switch child.Name.Local {
+		case "methods":
+			err = p.eachChild("method", func(methodStart xml.StartElement) error {
+				method, err := p.parseMethod(methodStart)
+				if err != nil {
+					return err
+				}
+				class.Methods = append(class.Methods, method)
+				return nil
+			})
+		case "lines":
+			classLines, err = p.parseLines()
+		default:
+			err = p.s.skip()
 		}
+		if err != nil {
+			return Class{}, err
+		}
+	}
 
-		pkg.Counters = sumClassCounters(pkg.Classes)
-		report.Packages = append(report.Packages, pkg)
+	class.Lines = linesFromCobertura(classLines)
+	if len(class.Methods) > 0 {
+		class.Counters = sumMethodCounters(class.Methods)
+	} else {
+		lineCounter, branchCounter := countersFromCoberturaLines(classLines)
+		class.Counters = normalizeCoberturaCounters(lineCounter, branchCounter)
 	}
+	return class, nil
+}
 
-	report.Counters = sumPackageCounters(report.Packages)
-	return report, nil
+func (p *coberturaParser) parseMethod(start xml.StartElement) (Method, error) {
+	var lines []coberturaLine
+	err := p.eachChild("lines", func(xml.StartElement) error {
+		parsed, err := p.parseLines()
+		lines = append(lines, parsed...)
+		return err
+	})
+	if err != nil {
+		return Method{}, err
+	}
+	lineCounter, branchCounter := countersFromCoberturaLines(lines)
+	return Method{
+		Name:     p.s.internAttr(start, "name"),
+		Desc:     p.s.internAttr(start, "signature"),
+		Line:     firstLineNumber(lines),
+		Counters: normalizeCoberturaCounters(lineCounter, branchCounter),
+	}, nil
 }
 
-func firstLineNumber(lines []xmlCoberturaLineNode) int {
+func (p *coberturaParser) parseLines() ([]coberturaLine, error) {
+	var lines []coberturaLine
+	err := p.eachChild("line", func(start xml.StartElement) error {
+		number, err := p.s.intAttr(start, "number")
+		if err != nil {
+			return err
+		}
+		hits, err := p.s.intAttr(start, "hits")
+		if err != nil {
+			return err
+		}
+		lines = append(lines, coberturaLine{
+			Number:            number,
+			Hits:              hits,
+			Branch:            p.s.attr(start, "branch") == "true",
+			ConditionCoverage: p.s.attr(start, "condition-coverage"),
+		})
+		return p.s.skip()
+	})
+	return lines, err
+}
+
+func firstLineNumber(lines []coberturaLine) int {
 	if len(lines) == 0 {
 		return 0
 	}
@@ -75,7 +206,36 @@ func firstLineNumber(lines []xmlCoberturaLineNode) int {
 	return min
 }
 
-func countersFromCoberturaLines(lines []xmlCoberturaLineNode) (Counter, Counter) {
+// linesFromCobertura converts Cobertura lines to the model, counting a line
+// as a single instruction that is covered when it has hits.
+func linesFromCobertura(lines []coberturaLine) []Line {
+	if len(lines) == 0 {
+		return nil
+	}
+	byNumber := make(map[int]int, len(lines))
+	out := make([]Line, 0, len(lines))
+	for _, cl := range lines {
+		line := Line{Number: cl.Number}
+		if cl.Hits > 0 {
+			line.CoveredInstructions = 1
+		} else {
+			line.MissedInstructions = 1
+		}
+		if cl.Branch {
+			line.CoveredBranches, line.MissedBranches = parseCoberturaBranchCoverage(cl)
+		}
+		if ix, ok := byNumber[cl.Number]; ok {
+			out[ix] = line
+			continue
+		}
+		byNumber[cl.Number] = len(out)
+		out = append(out, line)
+	}
+	sortLines(out)
+	return out
+}
+
+func countersFromCoberturaLines(lines []coberturaLine) (Counter, Counter) {
 	lineCounter := Counter{Type: CounterLine}
 	branchCounter := Counter{Type: CounterBranch}
 
@@ -86,7 +246,7 @@ func countersFromCoberturaLines(lines []xmlCoberturaLineNode) (Counter, Counter)
 			lineCounter.Missed++
 		}
 
-		if line.Branch == "true" {
+		if line.Branch {
 			covered, missed := parseCoberturaBranchCoverage(line)
 			branchCounter.Covered += covered
 			branchCounter.Missed += missed
@@ -96,7 +256,7 @@ func countersFromCoberturaLines(lines []xmlCoberturaLineNode) (Counter, Counter)
 	return lineCounter, branchCounter
 }
 
-func parseCoberturaBranchCoverage(line xmlCoberturaLineNode) (covered int, missed int) {
+func parseCoberturaBranchCoverage(line coberturaLine) (covered int, missed int) {
 	matches := coberturaConditionRe.FindStringSubmatch(line.ConditionCoverage)
 	if len(matches) == 3 {
 		c, err1 := strconv.Atoi(matches[1])
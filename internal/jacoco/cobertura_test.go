@@ -91,6 +91,9 @@ func TestParseCoberturaClassLinesFallback(t *testing.T) {
 	if !ok || c.Covered != 1 || c.Missed != 1 {
 		t.Fatalf("class line counter mismatch: %#v", c)
 	}
+	if len(class.Lines) != 2 || !class.Lines[0].Covered() || class.Lines[1].Covered() {
+		t.Fatalf("class lines mismatch: %#v", class.Lines)
+	}
 }
 
 func TestParseCoberturaRejectsInvalidXML(t *testing.T) {
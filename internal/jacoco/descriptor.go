@@ -0,0 +1,93 @@
+package jacoco
+
+import "strings"
+
+var primitiveTypes = map[byte]string{
+	'B': "byte",
+	'C': "char",
+	'D': "double",
+	'F': "float",
+	'I': "int",
+	'J': "long",
+	'S': "short",
+	'Z': "boolean",
+	'V': "void",
+}
+
+// DecodeDescriptor turns a JVM method descriptor such as
+// (Ljava/util/List;I)Ljava/lang/String; into Java type names, here List and
+// int returning String. With qualified, class names keep their package, as in
+// java.util.List. ok is false when desc is not a method descriptor.
+func DecodeDescriptor(desc string, qualified bool) (params []string, ret string, ok bool) {
+	rawParams, rawRet, ok := parseDescriptor(desc)
+	if !ok {
+		return nil, "", false
+	}
+	params = make([]string, len(rawParams))
+	for i, p := range rawParams {
+		params[i] = javaTypeName(p, qualified)
+	}
+	return params, javaTypeName(rawRet, qualified), true
+}
+
+// javaTypeName renders one descriptor field type. Nested classes are joined
+// with a dot, as in Map.Entry.
+func javaTypeName(t string, qualified bool) string {
+	dims := strings.Count(t, "[")
+	t = t[dims:]
+	name := primitiveTypes[t[0]]
+	if t[0] == 'L' {
+		name = strings.TrimSuffix(t[1:], ";")
+		if !qualified {
+			name = name[strings.LastIndexByte(name, '/')+1:]
+		}
+		name = strings.NewReplacer("/", ".", "$", ".").Replace(name)
+	}
+	return name + strings.Repeat("[]", dims)
+}
+
+// parseDescriptor splits a JVM method descriptor such as
+// (Ljava/lang/String;[I)V into its parameter types and return type.
+func parseDescriptor(desc string) (params []string, ret string, ok bool) {
+	if !strings.HasPrefix(desc, "(") {
+		return nil, "", false
+	}
+	rest := desc[1:]
+	for !strings.HasPrefix(rest, ")") {
+		t, n := descriptorType(rest)
+		if n == 0 {
+			return nil, "", false
+		}
+		params = append(params, t)
+		rest = rest[n:]
+	}
+	ret, n := descriptorType(rest[1:])
+	if n == 0 || n != len(rest)-1 {
+		return nil, "", false
+	}
+	return params, ret, true
+}
+
+// descriptorType reads one field type from the start of s and returns it with
+// its length, or a zero length when s does not start with a valid type.
+func descriptorType(s string) (string, int) {
+	n := 0
+	for n < len(s) && s[n] == '[' {
+		n++
+	}
+	if n == len(s) {
+		return "", 0
+	}
+	switch s[n] {
+	case 'B', 'C', 'D', 'F', 'I', 'J', 'S', 'Z', 'V':
+		return s[:n+1], n + 1
+	case 'L':
+		end := strings.IndexByte(s[n:], ';')
+		if end < 0 {
+			return "", 0
+		}
+		return s[:n+end+1], n + end + 1
+	default:
+		return "", 0
+	}
+}
@@ -0,0 +1,22 @@
+package jacoco
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeDescriptor(t *testing.T) {
+	params, ret, ok := DecodeDescriptor("(Ljava/util/List;I[Ljava/lang/String;)Ljava/util/Map$Entry;", false)
+	if !ok || !reflect.DeepEqual(params, []string{"List", "int", "String[]"}) || ret != "Map.Entry" {
+		t.Fatalf("decode mismatch: %v %s %v", params, ret, ok)
+	}
+	params, ret, ok = DecodeDescriptor("()[[Z", true)
+	if !ok || len(params) != 0 || ret != "boolean[][]" {
+		t.Fatalf("decode mismatch: %v %s %v", params, ret, ok)
+	}
+	for _, desc := range []string{"", "I", "(I", "(Q)V", "(Ljava/lang/String)V", "()VV"} {
+		if _, _, ok := DecodeDescriptor(desc, false); ok {
+			t.Fatalf("%q should not decode", desc)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package jacoco
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Filter selects the packages, classes and methods kept in a report.
+//
+// Patterns are globs matched against qualified names and source paths. Names
+// are split into segments at "." and "/", so "com.example.**" and
+// "com/example/**" are the same pattern; source paths are split at "/" only.
+// Within a segment, path.Match syntax applies, and a "**" segment matches any
+// number of segments. A pattern without a separator matches the last segment,
+// that is the simple name of a package, class or method, or the file name of
+// a source.
+type Filter struct {
+	exclude []namePattern
+	include []namePattern
+}
+
+type namePattern struct {
+	names []string
+	path  []string
+}
+
+// NewFilter compiles exclude and include patterns. With include patterns,
+// only the nodes that match one of them, or lie below one that does, are
+// kept.
+func NewFilter(exclude, include []string) (Filter, error) {
+	var f Filter
+	var err error
+	if f.exclude, err = compilePatterns(exclude); err != nil {
+		return Filter{}, err
+	}
+	if f.include, err = compilePatterns(include); err != nil {
+		return Filter{}, err
+	}
+	return f, nil
+}
+
+func compilePatterns(raw []string) ([]namePattern, error) {
+	patterns := make([]namePattern, 0, len(raw))
+	for _, p := range raw {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		pattern := namePattern{
+			names: splitName(p),
+			path:  strings.Split(strings.Trim(p, "/"), "/"),
+		}
+		for _, seg := range append(pattern.names, pattern.path...) {
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("パターンが不正です: %s", p)
+			}
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// Empty reports whether the filter keeps everything.
+func (f Filter) Empty() bool {
+	return len(f.exclude) == 0 && len(f.include) == 0
+}
+
+// FilterReport removes the nodes rejected by f and recomputes the counters of
+// the classes, packages, groups and report from what remains.
+func FilterReport(r Report, f Filter) Report {
+	if f.Empty() {
+		return r
+	}
+	r.Groups = f.groups(r.Groups)
+	r.Packages = f.packages(r.Packages)
+	r.Counters = sumContainerCounters(r.Groups, r.Packages)
+	return r
+}
+
+func (f Filter) groups(groups []Group) []Group {
+	out := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		g.Groups = f.groups(g.Groups)
+		g.Packages = f.packages(g.Packages)
+		if len(g.Groups) == 0 && len(g.Packages) == 0 {
+			continue
+		}
+		g.Counters = sumContainerCounters(g.Groups, g.Packages)
+		out = append(out, g)
+	}
+	return out
+}
+
+func (f Filter) packages(pkgs []Package) []Package {
+	out := make([]Package, 0, len(pkgs))
+	for _, p := range pkgs {
+		names := splitName(p.Name)
+		if matchAny(f.exclude, names, nil) {
+			continue
+		}
+		included := len(f.include) == 0 || matchAny(f.include, names, nil)
+		classes := make([]Class, 0, len(p.Classes))
+		for _, c := range p.Classes {
+			if c, ok := f.class(p.Name, c, included); ok {
+				classes = append(classes, c)
+			}
+		}
+		if len(classes) == 0 {
+			continue
+		}
+		p.Classes = classes
+		p.Counters = sumClassCounters(classes)
+		out = append(out, p)
+	}
+	return out
+}
+
+func (f Filter) class(pkg string, c Class, included bool) (Class, bool) {
+	names := splitName(c.Name)
+	source := sourcePath(pkg, c.SourceFileName)
+	if matchAny(f.exclude, names, source) {
+		return Class{}, false
+	}
+	included = included || matchAny(f.include, names, source)
+
+	methods := make([]Method, 0, len(c.Methods))
+	for _, m := range c.Methods {
+		methodNames := append(names[:len(names):len(names)], m.Name)
+		if matchAny(f.exclude, methodNames, nil) {
+			continue
+		}
+		if !included && !matchAny(f.include, methodNames, nil) {
+			continue
+		}
+		methods = append(methods, m)
+	}
+	if len(methods) == 0 {
+		// A class without method data stands on its own name.
+		return c, included && len(c.Methods) == 0
+	}
+	if len(methods) == len(c.Methods) {
+		return c, true
+	}
+	c.Lines = linesOfMethods(c.Lines, c.Methods, methods)
+	c.Methods = methods
+	c.Counters = classCountersFromMethods(methods, c.Lines)
+	return c, true
+}
+
+// linesOfMethods drops the lines of the methods that were left out of kept. A
+// method is taken to span from its first line to the first line of the next
+// method, as the report records no end line.
+func linesOfMethods(lines []Line, all, kept []Method) []Line {
+	if len(lines) == 0 {
+		return lines
+	}
+	keptStarts := map[int]bool{}
+	for _, m := range kept {
+		keptStarts[m.Line] = true
+	}
+	var starts []int
+	for _, m := range all {
+		if m.Line > 0 {
+			starts = append(starts, m.Line)
+		}
+	}
+	sort.Ints(starts)
+	dropped := func(n int) bool {
+		i := sort.SearchInts(starts, n+1) - 1
+		return i >= 0 && !keptStarts[starts[i]]
+	}
+	out := make([]Line, 0, len(lines))
+	for _, l := range lines {
+		if !dropped(l.Number) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// classCountersFromMethods rebuilds the counters of a class that lost some of
+// its methods. The LINE counter comes from the remaining lines when there are
+// any, since methods on the same line would count it twice. The class counts
+// as covered when a remaining method does.
+func classCountersFromMethods(methods []Method, lines []Line) []Counter {
+	agg := map[CounterType]Counter{}
+	for _, m := range methods {
+		mergeCounters(agg, m.Counters)
+	}
+	if len(lines) > 0 {
+		_, agg[CounterLine], _ = lineCounters(lines)
+	}
+	counters := mapToCounters(agg)
+	class := Counter{Type: CounterClass, Missed: 1}
+	for _, m := range methods {
+		if c, ok := m.Counter(CounterMethod); ok && c.Covered > 0 {
+			class = Counter{Type: CounterClass, Covered: 1}
+			break
+		}
+	}
+	return append(counters, class)
+}
+
+// sourcePath is the path of a class's source file as JaCoCo lays it out below
+// a source root. Formats that record a full path keep it.
+func sourcePath(pkg, file string) []string {
+	if file == "" {
+		return nil
+	}
+	if !strings.Contains(file, "/") && pkg != "" {
+		file = strings.ReplaceAll(pkg, ".", "/") + "/" + file
+	}
+	return strings.Split(strings.Trim(file, "/"), "/")
+}
+
+func splitName(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '/' })
+}
+
+func matchAny(patterns []namePattern, names, source []string) bool {
+	for _, p := range patterns {
+		if p.match(names, source) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p namePattern) match(names, source []string) bool {
+	if len(p.names) == 1 && len(names) > 0 && matchSegment(p.names[0], names[len(names)-1]) {
+		return true
+	}
+	if MatchSegments(p.names, names) {
+		return true
+	}
+	if len(source) == 0 {
+		return false
+	}
+	if len(p.path) == 1 {
+		return matchSegment(p.path[0], source[len(source)-1])
+	}
+	return MatchSegments(p.path, source)
+}
+
+// MatchSegments reports whether name matches pattern segment by segment, each
+// with path.Match syntax, where a "**" segment matches any number of segments.
+func MatchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if MatchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 || !matchSegment(pattern[0], name[0]) {
+		return false
+	}
+	return MatchSegments(pattern[1:], name[1:])
+}
+
+func matchSegment(pattern, name string) bool {
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
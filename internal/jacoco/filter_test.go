@@ -0,0 +1,144 @@
+package jacoco
+
+import "testing"
+
+func filterFixture() Report {
+	method := func(name string, missed, covered int) Method {
+		methodCounter := Counter{Type: CounterMethod, Missed: 1}
+		if covered > 0 {
+			methodCounter = Counter{Type: CounterMethod, Covered: 1}
+		}
+		return Method{Name: name, Counters: []Counter{
+			{Type: CounterInstruction, Missed: missed, Covered: covered},
+			methodCounter,
+		}}
+	}
+	class := func(name, source string, methods ...Method) Class {
+		c := Class{Name: name, SourceFileName: source, Methods: methods}
+		c.Counters = classCountersFromMethods(methods, nil)
+		return c
+	}
+	pkg := func(name string, classes ...Class) Package {
+		return Package{Name: name, Classes: classes, Counters: sumClassCounters(classes)}
+	}
+	pkgs := []Package{
+		pkg("com/example",
+			class("com/example/User", "User.java", method("getName", 0, 3), method("equals", 10, 0)),
+			class("com/example/User_Generated", "User_Generated.java", method("build", 20, 0)),
+		),
+		pkg("com/example/proto",
+			class("com/example/proto/Message", "Message.java", method("parse", 30, 0)),
+		),
+		pkg("com/example/service",
+			class("com/example/service/Service", "Service.java", method("run", 2, 8)),
+		),
+	}
+	return Report{Packages: pkgs, Counters: sumPackageCounters(pkgs)}
+}
+
+func TestFilterReportRecomputesCounters(t *testing.T) {
+	filter, err := NewFilter([]string{"*_Generated", "com.example.proto.**", "**.equals"}, nil)
+	if err != nil {
+		t.Fatalf("new filter failed: %v", err)
+	}
+	report := FilterReport(filterFixture(), filter)
+
+	if len(report.Packages) != 2 {
+		t.Fatalf("excluded package should be removed: %+v", report.Packages)
+	}
+	pkg := report.Packages[0]
+	if len(pkg.Classes) != 1 || pkg.Classes[0].Name != "com/example/User" {
+		t.Fatalf("generated class should be removed: %+v", pkg.Classes)
+	}
+	if len(pkg.Classes[0].Methods) != 1 {
+		t.Fatalf("excluded method should be removed: %+v", pkg.Classes[0].Methods)
+	}
+	if c, _ := pkg.Counter(CounterInstruction); c.Missed != 0 || c.Covered != 3 {
+		t.Fatalf("package counter mismatch: %+v", c)
+	}
+	if c, _ := pkg.Counter(CounterClass); c.Missed != 0 || c.Covered != 1 {
+		t.Fatalf("class counter mismatch: %+v", c)
+	}
+	if c, _ := report.Counter(CounterInstruction); c.Missed != 2 || c.Covered != 11 {
+		t.Fatalf("report counter mismatch: %+v", c)
+	}
+}
+
+func TestFilterReportIncludeAndSourcePaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude []string
+		include []string
+		want    []string
+	}{
+		{name: "include package", include: []string{"com/example/service"}, want: []string{"com/example/service/Service"}},
+		{name: "include method", include: []string{"**.parse"}, want: []string{"com/example/proto/Message"}},
+		{name: "exclude source path", exclude: []string{"com/example/*.java"}, want: []string{"com/example/proto/Message", "com/example/service/Service"}},
+		{name: "exclude source file name", exclude: []string{"Message.java", "User*.java"}, want: []string{"com/example/service/Service"}},
+		{name: "exclude wins over include", exclude: []string{"**.service.**"}, include: []string{"com.example.**"}, want: []string{"com/example/User", "com/example/User_Generated", "com/example/proto/Message"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := NewFilter(tc.exclude, tc.include)
+			if err != nil {
+				t.Fatalf("new filter failed: %v", err)
+			}
+			var got []string
+			for _, pkg := range FilterReport(filterFixture(), filter).Packages {
+				for _, c := range pkg.Classes {
+					got = append(got, c.Name)
+				}
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("classes mismatch: got %v want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("classes mismatch: got %v want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterReportDropsLinesOfExcludedMethods(t *testing.T) {
+	// run and its lambda share line 12, which LINE should count once.
+	class := Class{
+		Name: "com/example/Job",
+		Lines: []Line{
+			{Number: 3, CoveredInstructions: 2},
+			{Number: 11, CoveredInstructions: 1},
+			{Number: 12, CoveredInstructions: 4},
+			{Number: 20, MissedInstructions: 5},
+			{Number: 21, MissedInstructions: 1},
+		},
+		Methods: []Method{
+			{Name: "<init>", Line: 3, Counters: []Counter{{Type: CounterInstruction, Covered: 2}, {Type: CounterLine, Covered: 1}, {Type: CounterMethod, Covered: 1}}},
+			{Name: "run", Line: 11, Counters: []Counter{{Type: CounterInstruction, Covered: 3}, {Type: CounterLine, Covered: 2}, {Type: CounterMethod, Covered: 1}}},
+			{Name: "lambda$run$0", Line: 12, Counters: []Counter{{Type: CounterInstruction, Covered: 2}, {Type: CounterLine, Covered: 1}, {Type: CounterMethod, Covered: 1}}},
+			{Name: "debug", Line: 20, Counters: []Counter{{Type: CounterInstruction, Missed: 6}, {Type: CounterLine, Missed: 2}, {Type: CounterMethod, Missed: 1}}},
+		},
+	}
+	pkgs := []Package{{Name: "com/example", Classes: []Class{class}}}
+	filter, err := NewFilter([]string{"**.debug"}, nil)
+	if err != nil {
+		t.Fatalf("new filter failed: %v", err)
+	}
+
+	got := FilterReport(Report{Packages: pkgs}, filter).Packages[0].Classes[0]
+	if len(got.Lines) != 3 || got.Lines[2].Number != 12 {
+		t.Fatalf("lines of the excluded method should be dropped: %+v", got.Lines)
+	}
+	if c, _ := got.Counter(CounterLine); c.Missed != 0 || c.Covered != 3 {
+		t.Fatalf("line counter should come from the remaining lines: %+v", c)
+	}
+	if c, _ := got.Counter(CounterInstruction); c.Missed != 0 || c.Covered != 7 {
+		t.Fatalf("instruction counter mismatch: %+v", c)
+	}
+}
+
+func TestNewFilterRejectsBadPattern(t *testing.T) {
+	if _, err := NewFilter([]string{"com.[example"}, nil); err == nil {
+		t.Fatal("expected error for malformed pattern")
+	}
+}
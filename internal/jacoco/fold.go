@@ -0,0 +1,212 @@
+package jacoco
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	lambdaMethodRe   = regexp.MustCompile(`^lambda\$(.+)\$\d+$`)
+	accessorMethodRe = regexp.MustCompile(`^access\$\d+$`)
+)
+
+// FoldNestedClasses returns a copy of r in which nested, anonymous and
+// synthetic classes (Foo$Bar, Foo$1, Foo$$Lambda) are folded into their
+// top-level class. So are other top-level classes declared in the same source
+// file, such as a Kotlin FooKt facade. The top-level class keeps them in
+// Nested and its counters and lines include theirs. Lambda methods such as
+// lambda$doIt$0 are folded into the method that declares them.
+func FoldNestedClasses(r Report) Report {
+	view := r
+	view.Groups = foldGroups(r.Groups)
+	view.Packages = foldPackages(r.Packages)
+	return view
+}
+
+func foldGroups(groups []Group) []Group {
+	if groups == nil {
+		return nil
+	}
+	out := make([]Group, len(groups))
+	for i, g := range groups {
+		out[i] = g
+		out[i].Groups = foldGroups(g.Groups)
+		out[i].Packages = foldPackages(g.Packages)
+	}
+	return out
+}
+
+func foldPackages(pkgs []Package) []Package {
+	if pkgs == nil {
+		return nil
+	}
+	out := make([]Package, len(pkgs))
+	for i, p := range pkgs {
+		out[i] = p
+		out[i].Classes = foldClasses(p.Classes)
+	}
+	return out
+}
+
+func foldClasses(classes []Class) []Class {
+	byName := make(map[string]int, len(classes))
+	bySource := map[string]int{}
+	for i, c := range classes {
+		byName[c.Name] = i
+	}
+	for i, c := range classes {
+		if !strings.Contains(c.Name, "$") && c.SourceFileName != "" && path.Base(c.Name) == sourceBaseName(c.SourceFileName) {
+			bySource[c.SourceFileName] = i
+		}
+	}
+
+	outer := make([]int, len(classes))
+	for i, c := range classes {
+		outer[i] = i
+		if top, _, ok := strings.Cut(c.Name, "$"); ok {
+			if ix, ok := byName[top]; ok {
+				outer[i] = ix
+				continue
+			}
+		}
+		if ix, ok := bySource[c.SourceFileName]; ok && !strings.Contains(c.Name, "$") {
+			outer[i] = ix
+		}
+	}
+
+	nested := make(map[int][]Class)
+	for i, c := range classes {
+		if outer[i] != i {
+			nested[outer[i]] = append(nested[outer[i]], foldLambdas(c))
+		}
+	}
+	out := make([]Class, 0, len(classes)-len(nested))
+	for i, c := range classes {
+		if outer[i] != i {
+			continue
+		}
+		folded := foldLambdas(c)
+		if inner := nested[i]; len(inner) > 0 {
+			sort.SliceStable(inner, func(a, b int) bool { return inner[a].Name < inner[b].Name })
+			agg := map[CounterType]Counter{}
+			mergeCounters(agg, folded.Counters)
+			lines := append([]Line(nil), folded.Lines...)
+			for _, n := range inner {
+				mergeCounters(agg, n.Counters)
+				lines = mergeLines(lines, n.Lines, sumLine)
+			}
+			folded.Counters = mapToCounters(agg)
+			folded.Lines = lines
+			folded.Nested = inner
+		}
+		out = append(out, folded)
+	}
+	return out
+}
+
+func sourceBaseName(file string) string {
+	base := path.Base(file)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// foldLambdas adds the counters of lambda$name$N methods to the method name
+// they are declared in. Lambdas in constructors and static initializers are
+// named after new and static.
+func foldLambdas(c Class) Class {
+	byName := map[string]int{}
+	for i, m := range c.Methods {
+		if _, ok := byName[m.Name]; !ok {
+			byName[m.Name] = i
+		}
+	}
+	lambdas := map[int][]Method{}
+	var kept []int
+	for i, m := range c.Methods {
+		match := lambdaMethodRe.FindStringSubmatch(m.Name)
+		if match == nil {
+			kept = append(kept, i)
+			continue
+		}
+		owner := match[1]
+		switch owner {
+		case "new":
+			owner = "<init>"
+		case "static":
+			owner = "<clinit>"
+		}
+		ix, ok := byName[owner]
+		if !ok {
+			kept = append(kept, i)
+			continue
+		}
+		lambdas[ix] = append(lambdas[ix], m)
+	}
+	if len(lambdas) == 0 {
+		return c
+	}
+	methods := make([]Method, 0, len(kept))
+	for _, i := range kept {
+		m := c.Methods[i]
+		if folded := lambdas[i]; len(folded) > 0 {
+			agg := map[CounterType]Counter{}
+			mergeCounters(agg, m.Counters)
+			for _, l := range folded {
+				mergeCounters(agg, l.Counters)
+			}
+			m.Counters = mapToCounters(agg)
+		}
+		methods = append(methods, m)
+	}
+	c.Methods = methods
+	return c
+}
+
+// IsAccessorMethod reports whether m is a compiler generated accessor such as
+// access$000, going by its name.
+func IsAccessorMethod(m Method) bool {
+	return accessorMethodRe.MatchString(m.Name)
+}
+
+// IsBridgeMethod reports whether m looks like a bridge method, that is an
+// erased copy of another method of c whose reference types were widened to
+// java.lang.Object. This is a guess: a hand-written overload such as
+// equals(Object) next to equals(Foo) looks the same.
+func IsBridgeMethod(c Class, m Method) bool {
+	params, ret, ok := parseDescriptor(m.Desc)
+	if !ok {
+		return false
+	}
+	types := append(params[:len(params):len(params)], ret)
+	for _, other := range c.Methods {
+		if other.Name != m.Name || other.Desc == m.Desc {
+			continue
+		}
+		otherParams, otherRet, ok := parseDescriptor(other.Desc)
+		if ok && len(otherParams) == len(params) && widens(types, append(otherParams, otherRet)) {
+			return true
+		}
+	}
+	return false
+}
+
+// widens reports whether types equals specific except for reference types
+// replaced by java.lang.Object, at least one of them.
+func widens(types, specific []string) bool {
+	widened := false
+	for i := range types {
+		switch {
+		case types[i] == specific[i]:
+		case types[i] == "Ljava/lang/Object;" && isReferenceType(specific[i]):
+			widened = true
+		default:
+			return false
+		}
+	}
+	return widened
+}
+
+func isReferenceType(t string) bool {
+	return strings.HasPrefix(t, "L") || strings.HasPrefix(t, "[")
+}
@@ -0,0 +1,83 @@
+package jacoco
+
+import "testing"
+
+func instructions(missed, covered int) []Counter {
+	return []Counter{{Type: CounterInstruction, Missed: missed, Covered: covered}}
+}
+
+func TestFoldNestedClasses(t *testing.T) {
+	report := Report{Packages: []Package{{
+		Name: "com/example",
+		Classes: []Class{
+			{Name: "com/example/Foo", SourceFileName: "Foo.java", Counters: instructions(2, 8), Lines: []Line{{Number: 3, CoveredInstructions: 8}},
+				Methods: []Method{
+					{Name: "doIt", Counters: instructions(0, 4)},
+					{Name: "lambda$doIt$0", Counters: instructions(2, 0)},
+					{Name: "lambda$missing$1", Counters: instructions(0, 1)},
+				}},
+			{Name: "com/example/Foo$1", SourceFileName: "Foo.java", Counters: instructions(5, 0), Lines: []Line{{Number: 10, MissedInstructions: 5}}},
+			{Name: "com/example/Foo$Bar", SourceFileName: "Foo.java", Counters: instructions(0, 5)},
+			{Name: "com/example/Helper", SourceFileName: "Foo.java", Counters: instructions(1, 1)},
+			{Name: "com/example/Orphan$1", SourceFileName: "Orphan.java", Counters: instructions(1, 0)},
+		},
+	}}}
+
+	folded := FoldNestedClasses(report)
+	classes := folded.Packages[0].Classes
+	if len(classes) != 2 || classes[0].Name != "com/example/Foo" || classes[1].Name != "com/example/Orphan$1" {
+		t.Fatalf("top-level classes mismatch: %+v", classes)
+	}
+	foo := classes[0]
+	if len(foo.Nested) != 3 || foo.Nested[0].Name != "com/example/Foo$1" || foo.Nested[2].Name != "com/example/Helper" {
+		t.Fatalf("nested classes mismatch: %+v", foo.Nested)
+	}
+	if c, _ := foo.Counter(CounterInstruction); c.Missed != 8 || c.Covered != 14 {
+		t.Fatalf("rolled-up counter mismatch: %+v", c)
+	}
+	if len(foo.Lines) != 2 {
+		t.Fatalf("lines should be merged: %+v", foo.Lines)
+	}
+	if len(foo.Methods) != 2 || foo.Methods[0].Name != "doIt" || foo.Methods[1].Name != "lambda$missing$1" {
+		t.Fatalf("lambda should fold into its method: %+v", foo.Methods)
+	}
+	if c, _ := foo.Methods[0].Counter(CounterInstruction); c.Missed != 2 || c.Covered != 4 {
+		t.Fatalf("method counter mismatch: %+v", c)
+	}
+	if len(report.Packages[0].Classes) != 5 || report.Packages[0].Classes[0].Nested != nil {
+		t.Fatal("source report should stay unchanged")
+	}
+}
+
+func TestIsAccessorMethod(t *testing.T) {
+	methods := []Method{
+		{Name: "access$000", Desc: "(Lcom/example/Foo;)I"},
+		{Name: "lambda$doIt$0", Desc: "()V"},
+		{Name: "equals", Desc: "(Ljava/lang/Object;)Z"},
+		{Name: "add", Desc: "(Ljava/lang/Object;)V"},
+		{Name: "access", Desc: "()V"},
+	}
+	want := []bool{true, false, false, false, false}
+	for i, m := range methods {
+		if got := IsAccessorMethod(m); got != want[i] {
+			t.Fatalf("%s%s: got %v want %v", m.Name, m.Desc, got, want[i])
+		}
+	}
+}
+
+func TestIsBridgeMethod(t *testing.T) {
+	class := Class{Methods: []Method{
+		{Name: "compareTo", Desc: "(Lcom/example/Foo;)I"},
+		{Name: "compareTo", Desc: "(Ljava/lang/Object;)I"},
+		{Name: "get", Desc: "()Ljava/lang/String;"},
+		{Name: "get", Desc: "()Ljava/lang/Object;"},
+		{Name: "put", Desc: "(I)V"},
+		{Name: "put", Desc: "(J)V"},
+	}}
+	want := []bool{false, true, false, true, false, false}
+	for i, m := range class.Methods {
+		if got := IsBridgeMethod(class, m); got != want[i] {
+			t.Fatalf("%s%s: got %v want %v", m.Name, m.Desc, got, want[i])
+		}
+	}
+}
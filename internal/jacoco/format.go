@@ -7,8 +7,10 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 )
 
+// InputFormat names a registered report format, or FormatAuto.
 type InputFormat string
 
 const (
@@ -18,77 +20,159 @@ const (
 	FormatLCOV      InputFormat = "lcov"
 )
 
-func ParseWithFormatFile(path string, format InputFormat) (Report, error) {
-	switch format {
-	case FormatJaCoCo:
-		return ParseFile(path)
-	case FormatCobertura:
-		return ParseCoberturaFile(path)
-	case FormatLCOV:
-		return ParseLCOVFile(path)
-	case FormatAuto:
-		detected, err := DetectFormatFile(path)
+// ParseOptions selects the optional data a parse collects.
+type ParseOptions struct {
+	// Lines keeps the per-line coverage that JaCoCo stores apart from the
+	// classes, in <sourcefile> elements. Formats that record lines on the
+	// class keep them regardless.
+	Lines bool
+}
+
+// ParseWithFormatFile parses path as format, detecting the format first when
+// format is FormatAuto, and records the file metadata in Report.Inputs. path
+// may be StdinPath and may be compressed; the input is opened only once.
+func ParseWithFormatFile(path string, format InputFormat, opts ParseOptions) (Report, error) {
+	in, err := openInput(path)
+	if err != nil {
+		return Report{}, fmt.Errorf("open report: %w", err)
+	}
+	defer in.Close()
+
+	started := time.Now()
+	if format == FormatAuto {
+		prefix, err := in.prefix()
+		if err != nil {
+			return Report{}, fmt.Errorf("read report for format detection: %w", err)
+		}
+		format, err = detectFormatPrefix(prefix)
 		if err != nil {
 			return Report{}, err
 		}
-		return ParseWithFormatFile(path, detected)
-	default:
+	}
+
+	report, err := parseAs(in, format, opts)
+	if err != nil {
+		return Report{}, err
+	}
+
+	input := Input{Path: path, Format: format, ParseDuration: time.Since(started)}
+	if !IsStream(path) {
+		if info, err := os.Stat(path); err == nil {
+			input.ModTime = info.ModTime()
+		}
+	}
+	report.Inputs = []Input{input}
+	return report, nil
+}
+
+func parseAs(r io.Reader, format InputFormat, opts ParseOptions) (Report, error) {
+	p, ok := LookupParser(string(format))
+	if !ok {
 		return Report{}, fmt.Errorf("unsupported input format: %s", format)
 	}
+	if jp, ok := p.(jacocoFormat); ok && !opts.Lines {
+		return jp.parse(r, false)
+	}
+	return p.Parse(r)
 }
 
 func DetectFormatFile(path string) (InputFormat, error) {
-	f, err := os.Open(path)
+	in, err := OpenInput(path)
 	if err != nil {
 		return "", fmt.Errorf("open report for format detection: %w", err)
 	}
-	defer f.Close()
-	return DetectFormat(f)
+	defer in.Close()
+	return DetectFormat(in)
+}
+
+// ClaimsFile reports whether the parser of format recognizes the start of the
+// report at path, without asking the other parsers.
+func ClaimsFile(path string, format InputFormat) bool {
+	p, ok := LookupParser(string(format))
+	if !ok {
+		return false
+	}
+	in, err := OpenInput(path)
+	if err != nil {
+		return false
+	}
+	defer in.Close()
+	prefix, err := io.ReadAll(io.LimitReader(in, detectPrefixSize))
+	if err != nil || len(bytes.TrimSpace(prefix)) == 0 {
+		return false
+	}
+	return p.Detect(prefix) > ConfidenceNone
 }
 
+// detectPrefixSize bounds how much of a report is read for format detection.
+const detectPrefixSize = 64 << 10
+
+// DetectFormat identifies the report format from at most the first
+// detectPrefixSize bytes of r.
 func DetectFormat(r io.Reader) (InputFormat, error) {
-	data, err := io.ReadAll(r)
+	data, err := io.ReadAll(io.LimitReader(r, detectPrefixSize))
 	if err != nil {
 		return "", fmt.Errorf("read report for format detection: %w", err)
 	}
-	trimmed := strings.TrimSpace(string(data))
-	if trimmed == "" {
+	return detectFormatPrefix(data)
+}
+
+// detectFormatPrefix asks the registered parsers about prefix and picks the
+// most confident one, preferring earlier registrations on ties. Since ties go
+// to the earlier parser, the search stops at the first high confidence answer
+// and later (possibly external) parsers are not consulted.
+func detectFormatPrefix(prefix []byte) (InputFormat, error) {
+	if len(bytes.TrimSpace(prefix)) == 0 {
 		return "", fmt.Errorf("unsupported or empty report format")
 	}
-	if strings.HasPrefix(trimmed, "<") {
-		return detectXMLFormat(bytes.NewReader(data))
+	best := ConfidenceNone
+	var detected InputFormat
+	for _, rp := range registeredParsers() {
+		if c := rp.parser.Detect(prefix); c > best {
+			best = c
+			detected = InputFormat(rp.name)
+		}
+		if best == ConfidenceHigh {
+			break
+		}
+	}
+	if best == ConfidenceNone {
+		if root, ok := xmlRootElement(prefix); ok {
+			return "", fmt.Errorf("unsupported xml root element: %s", root)
+		}
+		return "", fmt.Errorf("unsupported report format")
 	}
-	return detectTextFormat(trimmed)
+	return detected, nil
 }
 
-func detectXMLFormat(r io.Reader) (InputFormat, error) {
-	dec := xml.NewDecoder(r)
+// xmlRootElement returns the local name of the document element when prefix
+// starts an XML document.
+func xmlRootElement(prefix []byte) (string, bool) {
+	trimmed := bytes.TrimSpace(prefix)
+	if len(trimmed) == 0 || trimmed[0] != '<' {
+		return "", false
+	}
+	dec := xml.NewDecoder(bytes.NewReader(trimmed))
 	for {
 		tok, err := dec.Token()
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return "", fmt.Errorf("detect format: %w", err)
+			return "", false
 		}
-		start, ok := tok.(xml.StartElement)
-		if !ok {
-			continue
-		}
-		switch start.Name.Local {
-		case "report":
-			return FormatJaCoCo, nil
-		case "coverage":
-			return FormatCobertura, nil
-		default:
-			return "", fmt.Errorf("unsupported xml root element: %s", start.Name.Local)
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, true
 		}
 	}
-	return "", fmt.Errorf("unsupported xml report format")
 }
 
-func detectTextFormat(text string) (InputFormat, error) {
-	for _, line := range strings.Split(text, "\n") {
+func detectXMLRoot(prefix []byte, root string) Confidence {
+	if got, ok := xmlRootElement(prefix); ok && got == root {
+		return ConfidenceHigh
+	}
+	return ConfidenceNone
+}
+
+func detectLCOV(prefix []byte) Confidence {
+	for _, line := range strings.Split(string(prefix), "\n") {
 		trim := strings.TrimSpace(line)
 		if trim == "" {
 			continue
@@ -98,9 +182,9 @@ func detectTextFormat(text string) (InputFormat, error) {
 			strings.HasPrefix(trim, "DA:") ||
 			strings.HasPrefix(trim, "FN:") ||
 			strings.HasPrefix(trim, "BRDA:") {
-			return FormatLCOV, nil
+			return ConfidenceHigh
 		}
 		break
 	}
-	return "", fmt.Errorf("unsupported text report format")
+	return ConfidenceNone
 }
@@ -1,6 +1,9 @@
 package jacoco
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -35,3 +38,69 @@ func TestDetectFormatRejectsUnknownRoot(t *testing.T) {
 		t.Fatal("expected error for unknown root")
 	}
 }
+
+func TestParseWithFormatFileRecordsInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jacoco.xml")
+	if err := os.WriteFile(path, []byte(`<report name="x"/>`), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	report, err := ParseWithFormatFile(path, FormatAuto, ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(report.Inputs) != 1 {
+		t.Fatalf("input count mismatch: %d", len(report.Inputs))
+	}
+	in := report.Inputs[0]
+	if in.Path != path || in.Format != FormatJaCoCo || in.ModTime.IsZero() {
+		t.Fatalf("unexpected input metadata: %#v", in)
+	}
+}
+
+func TestParseWithFormatFileKeepsLinesOnlyWhenAsked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jacoco.xml")
+	xml := `<report name="x"><package name="a">
+<class name="a/A" sourcefilename="A.java"><method name="run" desc="()V" line="3"/></class>
+<sourcefile name="A.java"><line nr="3" mi="0" ci="2" mb="0" cb="0"/></sourcefile>
+</package></report>`
+	if err := os.WriteFile(path, []byte(xml), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	for _, lines := range []bool{false, true} {
+		report, err := ParseWithFormatFile(path, FormatJaCoCo, ParseOptions{Lines: lines})
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		class := report.Packages[0].Classes[0]
+		if got := len(class.Lines) > 0; got != lines || len(class.Methods) != 1 {
+			t.Fatalf("Lines=%v: unexpected class %+v", lines, class)
+		}
+	}
+}
+
+type failAfterReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *failAfterReader) Read(p []byte) (int, error) {
+	if r.pos >= len(r.data) {
+		return 0, errors.New("read beyond detection prefix")
+	}
+	n := copy(p, r.data[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+func TestDetectFormatReadsBoundedPrefix(t *testing.T) {
+	prefix := []byte(`<?xml version="1.0"?><report name="big">` + strings.Repeat(" ", detectPrefixSize))
+	got, err := DetectFormat(&failAfterReader{data: prefix[:detectPrefixSize]})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	if got != FormatJaCoCo {
+		t.Fatalf("format mismatch: %s", got)
+	}
+}
@@ -0,0 +1,114 @@
+package jacoco
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// StdinPath is the path argument that reads the report from standard input.
+const StdinPath = "-"
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte("BZh")
+)
+
+// IsStream reports whether path refers to a stream that cannot be reopened or
+// watched, such as standard input.
+func IsStream(path string) bool {
+	return path == StdinPath
+}
+
+// OpenInput opens a report for reading. path may be StdinPath. gzip, zstd and
+// bzip2 compressed input is decompressed transparently based on its magic
+// bytes, whatever the file extension.
+func OpenInput(path string) (io.ReadCloser, error) {
+	in, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func openInput(path string) (*inputReader, error) {
+	var (
+		raw    io.Reader
+		closer io.Closer = io.NopCloser(nil)
+	)
+	if IsStream(path) {
+		raw = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		raw, closer = f, f
+	}
+
+	br := bufio.NewReaderSize(raw, detectPrefixSize)
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			closer.Close()
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		return &inputReader{Reader: bufio.NewReaderSize(zr, detectPrefixSize), closers: []io.Closer{zr, closer}}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			closer.Close()
+			return nil, fmt.Errorf("zstd: %w", err)
+		}
+		return &inputReader{Reader: bufio.NewReaderSize(zr, detectPrefixSize), closers: []io.Closer{zstdCloser{zr}, closer}}, nil
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return &inputReader{Reader: bufio.NewReaderSize(bzip2.NewReader(br), detectPrefixSize), closers: []io.Closer{closer}}, nil
+	default:
+		return &inputReader{Reader: br, closers: []io.Closer{closer}}, nil
+	}
+}
+
+// inputReader is a buffered, decompressed view of a report. The buffer holds
+// at least detectPrefixSize bytes so that the format can be sniffed with Peek
+// before the same stream is parsed.
+type inputReader struct {
+	*bufio.Reader
+	closers []io.Closer
+}
+
+func (r *inputReader) Close() error {
+	var first error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// prefix returns up to detectPrefixSize bytes without consuming them.
+func (r *inputReader) prefix() ([]byte, error) {
+	data, err := r.Peek(detectPrefixSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	return data, nil
+}
+
+type zstdCloser struct {
+	dec *zstd.Decoder
+}
+
+func (c zstdCloser) Close() error {
+	c.dec.Close()
+	return nil
+}
@@ -0,0 +1,109 @@
+package jacoco
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const compressedJaCoCo = `<report name="compressed"><package name="p"><class name="p/A"><counter type="LINE" missed="1" covered="3"/></class></package></report>`
+
+// bzip2Report is `<report name="bz"/>` compressed with bzip2 -9; the standard
+// library only provides a bzip2 reader.
+var bzip2Report = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x76, 0x3a,
+	0x7f, 0x76, 0x00, 0x00, 0x02, 0x19, 0x80, 0x50, 0x00, 0x80, 0x07, 0x32,
+	0x03, 0xd4, 0x10, 0x20, 0x00, 0x31, 0x4c, 0x00, 0x13, 0x42, 0x83, 0x08,
+	0x3d, 0x47, 0xea, 0x9e, 0x21, 0xa7, 0x72, 0x99, 0x0b, 0x86, 0x7a, 0x41,
+	0xf1, 0x77, 0x24, 0x53, 0x85, 0x09, 0x07, 0x63, 0xa7, 0xf7, 0x60,
+}
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(data)); err != nil {
+		t.Fatalf("gzip write failed: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("zstd writer failed: %v", err)
+	}
+	defer enc.Close()
+	return enc.EncodeAll([]byte(data), nil)
+}
+
+func TestParseWithFormatFileDecompressesByMagicBytes(t *testing.T) {
+	cases := []struct {
+		name     string
+		file     string
+		data     []byte
+		wantName string
+	}{
+		{name: "gzip", file: "jacoco.xml.gz", data: gzipBytes(t, compressedJaCoCo), wantName: "compressed"},
+		{name: "zstd", file: "jacoco.xml.zst", data: zstdBytes(t, compressedJaCoCo), wantName: "compressed"},
+		{name: "bzip2", file: "jacoco.xml.bz2", data: bzip2Report, wantName: "bz"},
+		{name: "gzip without extension", file: "report", data: gzipBytes(t, compressedJaCoCo), wantName: "compressed"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			if err := os.WriteFile(path, tc.data, 0o644); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			report, err := ParseWithFormatFile(path, FormatAuto, ParseOptions{})
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			if report.Name != tc.wantName {
+				t.Fatalf("report name mismatch: %s", report.Name)
+			}
+			if report.Inputs[0].Format != FormatJaCoCo {
+				t.Fatalf("format mismatch: %s", report.Inputs[0].Format)
+			}
+		})
+	}
+}
+
+func TestParseWithFormatFileReadsStdin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stdin")
+	content := "TN:\nSF:src/lib.rs\nDA:1,1\nDA:2,0\nend_of_record\n"
+	if err := os.WriteFile(path, zstdBytes(t, content), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer f.Close()
+	origStdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = origStdin
+	})
+
+	report, err := ParseWithFormatFile(StdinPath, FormatAuto, ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if report.Name != "lcov" {
+		t.Fatalf("report name mismatch: %s", report.Name)
+	}
+	in := report.Inputs[0]
+	if in.Path != StdinPath || !in.ModTime.IsZero() {
+		t.Fatalf("unexpected stdin input metadata: %#v", in)
+	}
+}
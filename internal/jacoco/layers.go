@@ -0,0 +1,331 @@
+package jacoco
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// annotateLayers records in merged how much each input report contributed,
+// one layer per report. roots[i] names the top-level group that reports[i]
+// was placed under, or is empty when it was merged at the root.
+//
+// Layers of methods and classes come from the inputs themselves; containers
+// sum the layers of their children. OnlyCovered is exact per line when every
+// input of a class carries line data and a lower bound otherwise.
+func annotateLayers(merged *Report, reports []Report, roots []string) {
+	merged.Layers = make([]string, len(reports))
+	for i, report := range reports {
+		merged.Layers[i] = layerName(report, i)
+	}
+
+	a := layerAnnotator{
+		layers:  len(reports),
+		nodes:   map[string][]Counter{},
+		classes: map[string][][]Line{},
+	}
+	a.indexContainer("", merged.Groups, merged.Packages)
+	for i, report := range reports {
+		prefix := ""
+		if roots != nil && roots[i] != "" {
+			prefix = groupKey("", roots[i])
+		}
+		a.collectContainer(i, prefix, report.Groups, report.Packages)
+	}
+	a.finishContainer("", merged.Groups, merged.Packages)
+	merged.Counters = a.sumLayers(merged.Counters, childCounters(merged.Groups, merged.Packages))
+}
+
+func layerName(report Report, i int) string {
+	if len(report.Inputs) > 0 && report.Inputs[0].Path != "" {
+		if IsStream(report.Inputs[0].Path) {
+			return "(stdin)"
+		}
+		return filepath.ToSlash(report.Inputs[0].Path)
+	}
+	if report.Name != "" {
+		return report.Name
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+type layerAnnotator struct {
+	layers int
+	// nodes maps the key of every merged class and method to its counters.
+	nodes map[string][]Counter
+	// classes holds, per merged class, the lines each layer reported.
+	classes map[string][][]Line
+}
+
+func groupKey(parent, name string) string   { return parent + "/g:" + name }
+func packageKey(parent, name string) string { return parent + "/p:" + name }
+func classKey(pkg, name string) string      { return pkg + "/c:" + name }
+func methodKey(class string, m Method) string {
+	return class + "/m:" + methodMergeKey(m)
+}
+
+func (a *layerAnnotator) resetLayers(counters []Counter) {
+	for i := range counters {
+		counters[i].Layers = make([]LayerCounter, a.layers)
+	}
+}
+
+func (a *layerAnnotator) indexContainer(key string, groups []Group, pkgs []Package) {
+	for _, group := range groups {
+		a.indexContainer(groupKey(key, group.Name), group.Groups, group.Packages)
+	}
+	for _, pkg := range pkgs {
+		pk := packageKey(key, pkg.Name)
+		for _, class := range pkg.Classes {
+			ck := classKey(pk, class.Name)
+			a.resetLayers(class.Counters)
+			a.nodes[ck] = class.Counters
+			for _, method := range class.Methods {
+				a.resetLayers(method.Counters)
+				a.nodes[methodKey(ck, method)] = method.Counters
+			}
+		}
+	}
+}
+
+func (a *layerAnnotator) collectContainer(layer int, key string, groups []Group, pkgs []Package) {
+	for _, group := range groups {
+		a.collectContainer(layer, groupKey(key, group.Name), group.Groups, group.Packages)
+	}
+	for _, pkg := range pkgs {
+		pk := packageKey(key, pkg.Name)
+		for _, class := range pkg.Classes {
+			ck := classKey(pk, class.Name)
+			a.addLayer(a.nodes[ck], layer, class.Counters)
+			lines, ok := a.classes[ck]
+			if !ok {
+				lines = make([][]Line, a.layers)
+				a.classes[ck] = lines
+			}
+			// An empty, non-nil slice marks the layer as present.
+			lines[layer] = append(lines[layer], class.Lines...)
+			if lines[layer] == nil {
+				lines[layer] = []Line{}
+			}
+			for _, method := range class.Methods {
+				a.addLayer(a.nodes[methodKey(ck, method)], layer, method.Counters)
+			}
+		}
+	}
+}
+
+func (a *layerAnnotator) addLayer(dst []Counter, layer int, src []Counter) {
+	for _, c := range src {
+		for i := range dst {
+			if dst[i].Type == c.Type {
+				dst[i].Layers[layer].Missed += c.Missed
+				dst[i].Layers[layer].Covered += c.Covered
+			}
+		}
+	}
+}
+
+// finishContainer fills OnlyCovered for classes and methods and derives the
+// layers of every container from its children.
+func (a *layerAnnotator) finishContainer(key string, groups []Group, pkgs []Package) {
+	for i := range groups {
+		group := &groups[i]
+		a.finishContainer(groupKey(key, group.Name), group.Groups, group.Packages)
+		group.Counters = a.sumLayers(group.Counters, childCounters(group.Groups, group.Packages))
+	}
+	for i := range pkgs {
+		pkg := &pkgs[i]
+		pk := packageKey(key, pkg.Name)
+		children := make([][]Counter, 0, len(pkg.Classes))
+		for _, class := range pkg.Classes {
+			ck := classKey(pk, class.Name)
+			setOnlyCovered(class.Counters)
+			a.setLineOnlyCovered(class.Counters, a.classes[ck])
+			for _, method := range class.Methods {
+				setOnlyCovered(method.Counters)
+			}
+			children = append(children, class.Counters)
+		}
+		pkg.Counters = a.sumLayers(pkg.Counters, children)
+	}
+}
+
+func childCounters(groups []Group, pkgs []Package) [][]Counter {
+	children := make([][]Counter, 0, len(groups)+len(pkgs))
+	for _, group := range groups {
+		children = append(children, group.Counters)
+	}
+	for _, pkg := range pkgs {
+		children = append(children, pkg.Counters)
+	}
+	return children
+}
+
+// sumLayers sets the layers of a container's counters to the sum over its
+// children. A counter copy is made so that shared slices are not modified.
+func (a *layerAnnotator) sumLayers(counters []Counter, children [][]Counter) []Counter {
+	out := make([]Counter, len(counters))
+	copy(out, counters)
+	for i := range out {
+		out[i].Layers = make([]LayerCounter, a.layers)
+		for _, child := range children {
+			for _, c := range child {
+				if c.Type != out[i].Type || len(c.Layers) != a.layers {
+					continue
+				}
+				for l, lc := range c.Layers {
+					out[i].Layers[l].Missed += lc.Missed
+					out[i].Layers[l].Covered += lc.Covered
+					out[i].Layers[l].OnlyCovered += lc.OnlyCovered
+				}
+			}
+		}
+	}
+	return out
+}
+
+// setOnlyCovered estimates exclusive coverage from counters alone: whatever a
+// layer covers beyond the best other layer cannot have been covered by it.
+func setOnlyCovered(counters []Counter) {
+	for i := range counters {
+		layers := counters[i].Layers
+		for l := range layers {
+			best := 0
+			for o := range layers {
+				if o != l {
+					best = max(best, layers[o].Covered)
+				}
+			}
+			layers[l].OnlyCovered = max(0, layers[l].Covered-best)
+		}
+	}
+}
+
+// setLineOnlyCovered replaces the estimate for INSTRUCTION, LINE and BRANCH
+// with per-line values when every layer that has the class has line data.
+func (a *layerAnnotator) setLineOnlyCovered(counters []Counter, perLayer [][]Line) {
+	if perLayer == nil {
+		return
+	}
+	byNumber := make([]map[int]Line, a.layers)
+	for l, lines := range perLayer {
+		if lines == nil {
+			continue
+		}
+		if len(lines) == 0 {
+			return
+		}
+		byNumber[l] = make(map[int]Line, len(lines))
+		for _, line := range lines {
+			byNumber[l][line.Number] = line
+		}
+	}
+
+	only := make([][3]int, a.layers) // instruction, line, branch
+	for l := range byNumber {
+		for number, line := range byNumber[l] {
+			var otherCI, otherCB int
+			for o := range byNumber {
+				if o == l || byNumber[o] == nil {
+					continue
+				}
+				other := byNumber[o][number]
+				otherCI = max(otherCI, other.CoveredInstructions)
+				otherCB = max(otherCB, other.CoveredBranches)
+			}
+			only[l][0] += max(0, line.CoveredInstructions-otherCI)
+			if line.Covered() && otherCI == 0 {
+				only[l][1]++
+			}
+			only[l][2] += max(0, line.CoveredBranches-otherCB)
+		}
+	}
+
+	for i := range counters {
+		ix := -1
+		switch counters[i].Type {
+		case CounterInstruction:
+			ix = 0
+		case CounterLine:
+			ix = 1
+		case CounterBranch:
+			ix = 2
+		}
+		if ix < 0 {
+			continue
+		}
+		for l := range counters[i].Layers {
+			if byNumber[l] != nil {
+				counters[i].Layers[l].OnlyCovered = only[l][ix]
+			}
+		}
+	}
+}
+
+// LayerView returns a copy of r whose counters show a single layer: what the
+// input measured, or with only set, what it covers that no other input does.
+// In the latter case the totals stay those of the merged report, so the rate
+// reads as the share of the code covered by that input alone.
+func LayerView(r Report, layer int, only bool) Report {
+	if layer < 0 || layer >= len(r.Layers) {
+		return r
+	}
+	view := r
+	view.Counters = layerCounters(r.Counters, layer, only)
+	view.Groups = layerGroups(r.Groups, layer, only)
+	view.Packages = layerPackages(r.Packages, layer, only)
+	return view
+}
+
+func layerGroups(groups []Group, layer int, only bool) []Group {
+	if groups == nil {
+		return nil
+	}
+	out := make([]Group, len(groups))
+	for i, group := range groups {
+		out[i] = group
+		out[i].Counters = layerCounters(group.Counters, layer, only)
+		out[i].Groups = layerGroups(group.Groups, layer, only)
+		out[i].Packages = layerPackages(group.Packages, layer, only)
+	}
+	return out
+}
+
+func layerPackages(pkgs []Package, layer int, only bool) []Package {
+	if pkgs == nil {
+		return nil
+	}
+	out := make([]Package, len(pkgs))
+	for i, pkg := range pkgs {
+		out[i] = pkg
+		out[i].Counters = layerCounters(pkg.Counters, layer, only)
+		out[i].Classes = make([]Class, len(pkg.Classes))
+		for j, class := range pkg.Classes {
+			out[i].Classes[j] = class
+			out[i].Classes[j].Counters = layerCounters(class.Counters, layer, only)
+			out[i].Classes[j].Methods = make([]Method, len(class.Methods))
+			for k, method := range class.Methods {
+				out[i].Classes[j].Methods[k] = method
+				out[i].Classes[j].Methods[k].Counters = layerCounters(method.Counters, layer, only)
+			}
+		}
+	}
+	return out
+}
+
+func layerCounters(counters []Counter, layer int, only bool) []Counter {
+	out := make([]Counter, 0, len(counters))
+	for _, c := range counters {
+		view := Counter{Type: c.Type}
+		if layer < len(c.Layers) {
+			lc := c.Layers[layer]
+			if only {
+				view.Covered = lc.OnlyCovered
+				view.Missed = c.Total() - lc.OnlyCovered
+			} else {
+				view.Missed, view.Covered = lc.Missed, lc.Covered
+			}
+		}
+		out = append(out, view)
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package jacoco
+
+import "testing"
+
+func TestMergeReportsWithRecordsLayers(t *testing.T) {
+	unit := lineReport(
+		Line{Number: 1, CoveredInstructions: 2},
+		Line{Number: 2, MissedInstructions: 3},
+		Line{Number: 3, CoveredInstructions: 1, MissedBranches: 1, CoveredBranches: 1},
+	)
+	unit.Inputs = []Input{{Path: "unit/jacoco.xml", Format: FormatJaCoCo}}
+	integration := lineReport(
+		Line{Number: 1, CoveredInstructions: 2},
+		Line{Number: 2, CoveredInstructions: 3},
+		Line{Number: 3, MissedInstructions: 1, MissedBranches: 2},
+	)
+	integration.Inputs = []Input{{Path: "it/jacoco.xml", Format: FormatJaCoCo}}
+
+	merged := MergeReportsWith(MergeUnion, unit, integration)
+	if len(merged.Layers) != 2 || merged.Layers[0] != "unit/jacoco.xml" || merged.Layers[1] != "it/jacoco.xml" {
+		t.Fatalf("layer names mismatch: %v", merged.Layers)
+	}
+
+	class := merged.Packages[0].Classes[0]
+	line, _ := class.Counter(CounterLine)
+	if len(line.Layers) != 2 {
+		t.Fatalf("class counter should carry layers: %#v", line)
+	}
+	if got := line.Layers[0]; got.Covered != 2 || got.Missed != 1 || got.OnlyCovered != 1 {
+		t.Fatalf("unit layer mismatch: %#v", got)
+	}
+	if got := line.Layers[1]; got.Covered != 2 || got.Missed != 1 || got.OnlyCovered != 1 {
+		t.Fatalf("integration layer mismatch: %#v", got)
+	}
+	branch, _ := class.Counter(CounterBranch)
+	if got := branch.Layers[0]; got.OnlyCovered != 1 {
+		t.Fatalf("unit branch layer mismatch: %#v", got)
+	}
+
+	root, _ := merged.Counter(CounterLine)
+	if len(root.Layers) != 2 || root.Layers[1].OnlyCovered != 1 {
+		t.Fatalf("report counters should sum layers: %#v", root)
+	}
+}
+
+func TestLayerViewShowsOneLayer(t *testing.T) {
+	unit := lineReport(Line{Number: 1, CoveredInstructions: 1}, Line{Number: 2, MissedInstructions: 1})
+	integration := lineReport(Line{Number: 1, CoveredInstructions: 1}, Line{Number: 2, CoveredInstructions: 1})
+	merged := MergeReportsWith(MergeUnion, unit, integration)
+
+	view := LayerView(merged, 0, false)
+	if line, _ := view.Counter(CounterLine); line.Covered != 1 || line.Missed != 1 {
+		t.Fatalf("layer view mismatch: %#v", line)
+	}
+	only := LayerView(merged, 1, true)
+	if line, _ := only.Packages[0].Classes[0].Counter(CounterLine); line.Covered != 1 || line.Missed != 1 {
+		t.Fatalf("only-layer view mismatch: %#v", line)
+	}
+	if line, _ := merged.Counter(CounterLine); line.Covered != 2 {
+		t.Fatalf("layer view should not modify the merged report: %#v", line)
+	}
+}
+
+func TestLayersWithoutLineDataUseCounterEstimate(t *testing.T) {
+	report := func(covered int) Report {
+		counters := []Counter{{Type: CounterMethod, Missed: 4 - covered, Covered: covered}}
+		return Report{Packages: []Package{{
+			Name:     "p",
+			Classes:  []Class{{Name: "p/A", Counters: counters}},
+			Counters: counters,
+		}}}
+	}
+
+	merged := MergeReportsWith(MergeMax, report(1), report(3))
+	method, _ := merged.Packages[0].Classes[0].Counter(CounterMethod)
+	if method.Layers[0].OnlyCovered != 0 || method.Layers[1].OnlyCovered != 2 {
+		t.Fatalf("only covered estimate mismatch: %#v", method.Layers)
+	}
+}
+
+func TestGroupReportsRecordsLayersAcrossGroups(t *testing.T) {
+	a := lineReport(Line{Number: 1, CoveredInstructions: 1})
+	b := lineReport(Line{Number: 1, MissedInstructions: 1})
+
+	grouped := GroupReports(MergeUnion, []Report{a, b}, []string{"a", "b"})
+	if len(grouped.Layers) != 2 {
+		t.Fatalf("expected two layers, got %v", grouped.Layers)
+	}
+	line, _ := grouped.Groups[0].Counter(CounterLine)
+	if line.Layers[0].Covered != 1 || line.Layers[1] != (LayerCounter{}) {
+		t.Fatalf("group a should only hold layer 0: %#v", line.Layers)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -17,15 +16,21 @@ type lcovMethod struct {
 	hits int
 }
 
+type lcovBranch struct {
+	line    int
+	covered int
+	missed  int
+}
+
 type lcovRecord struct {
 	sourcePath string
 	lines      map[int]int
-	branches   [][2]int // covered, missed contribution per BRDA
+	branches   []lcovBranch
 	methods    map[string]lcovMethod
 }
 
 func ParseLCOVFile(path string) (Report, error) {
-	f, err := os.Open(path)
+	f, err := OpenInput(path)
 	if err != nil {
 		return Report{}, fmt.Errorf("open lcov report: %w", err)
 	}
@@ -87,9 +92,9 @@ func ParseLCOV(r io.Reader) (Report, error) {
 			if !inRecord {
 				continue
 			}
-			covered, missed, ok := parseLCOVBRDA(line)
+			lineNo, covered, missed, ok := parseLCOVBRDA(line)
 			if ok {
-				current.branches = append(current.branches, [2]int{covered, missed})
+				current.branches = append(current.branches, lcovBranch{line: lineNo, covered: covered, missed: missed})
 			}
 		case line == "end_of_record":
 			if inRecord && current.sourcePath != "" {
@@ -183,23 +188,27 @@ func parseLCOVFNDA(line string) (name string, hits int, ok bool) {
 	return strings.TrimSpace(parts[1]), h, true
 }
 
-func parseLCOVBRDA(line string) (covered int, missed int, ok bool) {
+func parseLCOVBRDA(line string) (lineNo int, covered int, missed int, ok bool) {
 	parts := strings.Split(strings.TrimPrefix(line, "BRDA:"), ",")
 	if len(parts) != 4 {
-		return 0, 0, false
+		return 0, 0, 0, false
+	}
+	lineNo, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, 0, false
 	}
 	taken := strings.TrimSpace(parts[3])
 	if taken == "-" {
-		return 0, 1, true
+		return lineNo, 0, 1, true
 	}
 	v, err := strconv.Atoi(taken)
 	if err != nil {
-		return 0, 0, false
+		return 0, 0, 0, false
 	}
 	if v > 0 {
-		return 1, 0, true
+		return lineNo, 1, 0, true
 	}
-	return 0, 1, true
+	return lineNo, 0, 1, true
 }
 
 func normalizeLCOVNames(sourcePath string) (pkg string, class string) {
@@ -230,8 +239,8 @@ func lcovRecordToClass(className, sourcePath string, rec lcovRecord) Class {
 
 	branchCounter := Counter{Type: CounterBranch}
 	for _, b := range rec.branches {
-		branchCounter.Covered += b[0]
-		branchCounter.Missed += b[1]
+		branchCounter.Covered += b.covered
+		branchCounter.Missed += b.missed
 	}
 
 	methods := make([]Method, 0, len(rec.methods))
@@ -263,6 +272,41 @@ func lcovRecordToClass(className, sourcePath string, rec lcovRecord) Class {
 		Name:           className,
 		SourceFileName: filepath.ToSlash(sourcePath),
 		Methods:        methods,
+		Lines:          lcovLines(rec),
 		Counters:       counters,
 	}
 }
+
+// lcovLines converts DA and BRDA records to the model, counting a line as a
+// single instruction that is covered when it has hits. Branches on lines
+// without a DA record still get a line entry so that no branch is lost.
+func lcovLines(rec lcovRecord) []Line {
+	if len(rec.lines) == 0 && len(rec.branches) == 0 {
+		return nil
+	}
+	byNumber := make(map[int]*Line, len(rec.lines))
+	for number, hits := range rec.lines {
+		line := &Line{Number: number}
+		if hits > 0 {
+			line.CoveredInstructions = 1
+		} else {
+			line.MissedInstructions = 1
+		}
+		byNumber[number] = line
+	}
+	for _, b := range rec.branches {
+		line, ok := byNumber[b.line]
+		if !ok {
+			line = &Line{Number: b.line}
+			byNumber[b.line] = line
+		}
+		line.CoveredBranches += b.covered
+		line.MissedBranches += b.missed
+	}
+	lines := make([]Line, 0, len(byNumber))
+	for _, line := range byNumber {
+		lines = append(lines, *line)
+	}
+	sortLines(lines)
+	return lines
+}
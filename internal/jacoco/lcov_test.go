@@ -58,3 +58,25 @@ func TestParseLCOVRejectsEmptyInput(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+func TestParseLCOVKeepsLineData(t *testing.T) {
+	text := "SF:src/a.ts\nDA:2,0\nDA:1,4\nBRDA:2,0,0,-\nBRDA:2,0,1,1\nend_of_record\n"
+
+	report, err := ParseLCOV(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("parse lcov failed: %v", err)
+	}
+	lines := report.Packages[0].Classes[0].Lines
+	want := []Line{
+		{Number: 1, CoveredInstructions: 1},
+		{Number: 2, MissedInstructions: 1, MissedBranches: 1, CoveredBranches: 1},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("line count mismatch: %#v", lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("line %d mismatch: got %#v, want %#v", i, lines[i], want[i])
+		}
+	}
+}
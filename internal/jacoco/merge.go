@@ -5,36 +5,196 @@ import (
 	"strconv"
 )
 
-// MergeReports merges multiple JaCoCo reports by package/class/method identity.
+// MergeStrategy decides how counters of the same node in several reports are
+// combined.
+type MergeStrategy string
+
+const (
+	// MergeSum adds counters up. It suits reports over disjoint code, such
+	// as one report per module.
+	MergeSum MergeStrategy = "sum"
+	// MergeUnion treats a line as covered when any report covers it. Classes
+	// without usable line data fall back to MergeMax.
+	MergeUnion MergeStrategy = "union"
+	// MergeMax keeps the best counter of each class and method, for reports
+	// that cover the same code but carry no line data.
+	MergeMax MergeStrategy = "max"
+)
+
+// MergeStrategies lists the valid merge strategies.
+func MergeStrategies() []MergeStrategy {
+	return []MergeStrategy{MergeSum, MergeUnion, MergeMax}
+}
+
+// MergeReports merges multiple JaCoCo reports by group/package/class/method
+// identity, adding their counters up.
 func MergeReports(reports ...Report) Report {
+	return MergeReportsWith(MergeSum, reports...)
+}
+
+// MergeReportsWith merges reports like MergeReports, combining the counters
+// of nodes present in several reports according to strategy. With union and
+// max, counters above classes are recomputed from the merged classes. Each
+// report becomes a layer of the result, see Counter.Layers.
+func MergeReportsWith(strategy MergeStrategy, reports ...Report) Report {
+	merged := mergeReports(strategy, reports)
+	if len(reports) > 1 {
+		annotateLayers(&merged, reports, nil)
+	}
+	return merged
+}
+
+func mergeReports(strategy MergeStrategy, reports []Report) Report {
 	if len(reports) == 0 {
 		return Report{}
 	}
 
+	m := merger{strategy: strategy}
 	merged := Report{Name: reports[0].Name}
-	pkgIndex := map[string]int{}
+	for _, report := range reports {
+		merged.Sessions = append(merged.Sessions, report.Sessions...)
+		merged.Inputs = append(merged.Inputs, report.Inputs...)
+		merged.Counters = m.counters(merged.Counters, report.Counters)
+		merged.Groups = m.mergeGroups(merged.Groups, report.Groups)
+		merged.Packages = m.mergePackages(merged.Packages, report.Packages)
+	}
+	if strategy != MergeSum && len(reports) > 1 {
+		recomputeGroupCounters(merged.Groups)
+		recomputePackageCounters(merged.Packages)
+		if len(merged.Groups) > 0 || len(merged.Packages) > 0 {
+			merged.Counters = sumContainerCounters(merged.Groups, merged.Packages)
+		}
+	}
+	return merged
+}
+
+// GroupReports merges reports under one top-level group per distinct label,
+// where labels[i] names the group of reports[i]. Reports sharing a label are
+// merged with MergeReportsWith and strategy. Counter types other than LINE and BRANCH are
+// measured differently by each format, so when the inputs mix formats the
+// root aggregates only those two.
+func GroupReports(strategy MergeStrategy, reports []Report, labels []string) Report {
+	if len(reports) == 0 {
+		return Report{}
+	}
+
+	order := make([]string, 0, len(reports))
+	byLabel := map[string][]Report{}
+	for i, report := range reports {
+		label := labels[i]
+		if _, ok := byLabel[label]; !ok {
+			order = append(order, label)
+		}
+		byLabel[label] = append(byLabel[label], report)
+	}
 
+	grouped := Report{}
+	if len(reports) == 1 {
+		grouped.Name = reports[0].Name
+	}
+	for _, label := range order {
+		merged := mergeReports(strategy, byLabel[label])
+		grouped.Sessions = append(grouped.Sessions, merged.Sessions...)
+		grouped.Inputs = append(grouped.Inputs, merged.Inputs...)
+		grouped.Groups = append(grouped.Groups, Group{
+			Name:     label,
+			Groups:   merged.Groups,
+			Packages: merged.Packages,
+			Counters: merged.Counters,
+		})
+	}
+	grouped.Counters = sumContainerCounters(grouped.Groups, nil)
+	if MixedFormats(reports) {
+		grouped.Counters = formatNeutralCounters(grouped.Counters)
+	}
+	if len(reports) > 1 {
+		annotateLayers(&grouped, reports, labels)
+	}
+	return grouped
+}
+
+// MixedFormats reports whether the inputs of reports were read in more than
+// one format.
+func MixedFormats(reports []Report) bool {
+	var first InputFormat
 	for _, report := range reports {
-		merged.Counters = mergeCounterSlices(merged.Counters, report.Counters)
-		for _, pkg := range report.Packages {
-			ix, ok := pkgIndex[pkg.Name]
-			if !ok {
-				pkgIndex[pkg.Name] = len(merged.Packages)
-				merged.Packages = append(merged.Packages, Package{Name: pkg.Name})
-				ix = len(merged.Packages) - 1
+		for _, input := range report.Inputs {
+			if first == "" {
+				first = input.Format
+				continue
+			}
+			if input.Format != first {
+				return true
 			}
-			mergePackage(&merged.Packages[ix], pkg)
 		}
 	}
+	return false
+}
 
-	sort.SliceStable(merged.Packages, func(i, j int) bool {
-		return merged.Packages[i].Name < merged.Packages[j].Name
+func formatNeutralCounters(counters []Counter) []Counter {
+	out := make([]Counter, 0, 2)
+	for _, c := range counters {
+		if c.Type == CounterLine || c.Type == CounterBranch {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+type merger struct {
+	strategy MergeStrategy
+}
+
+func (m merger) mergeGroups(dst []Group, src []Group) []Group {
+	if len(src) == 0 {
+		return dst
+	}
+	groupIndex := map[string]int{}
+	for i, group := range dst {
+		groupIndex[group.Name] = i
+	}
+	for _, group := range src {
+		ix, ok := groupIndex[group.Name]
+		if !ok {
+			dst = append(dst, Group{Name: group.Name})
+			ix = len(dst) - 1
+			groupIndex[group.Name] = ix
+		}
+		dst[ix].Counters = m.counters(dst[ix].Counters, group.Counters)
+		dst[ix].Groups = m.mergeGroups(dst[ix].Groups, group.Groups)
+		dst[ix].Packages = m.mergePackages(dst[ix].Packages, group.Packages)
+	}
+	sort.SliceStable(dst, func(i, j int) bool {
+		return dst[i].Name < dst[j].Name
 	})
-	return merged
+	return dst
 }
 
-func mergePackage(dst *Package, src Package) {
-	dst.Counters = mergeCounterSlices(dst.Counters, src.Counters)
+func (m merger) mergePackages(dst []Package, src []Package) []Package {
+	if len(src) == 0 {
+		return dst
+	}
+	pkgIndex := map[string]int{}
+	for i, pkg := range dst {
+		pkgIndex[pkg.Name] = i
+	}
+	for _, pkg := range src {
+		ix, ok := pkgIndex[pkg.Name]
+		if !ok {
+			dst = append(dst, Package{Name: pkg.Name})
+			ix = len(dst) - 1
+			pkgIndex[pkg.Name] = ix
+		}
+		m.mergePackage(&dst[ix], pkg)
+	}
+	sort.SliceStable(dst, func(i, j int) bool {
+		return dst[i].Name < dst[j].Name
+	})
+	return dst
+}
+
+func (m merger) mergePackage(dst *Package, src Package) {
+	dst.Counters = m.counters(dst.Counters, src.Counters)
 
 	classIndex := map[string]int{}
 	for i, class := range dst.Classes {
@@ -48,7 +208,7 @@ func mergePackage(dst *Package, src Package) {
 			ix = len(dst.Classes) - 1
 			classIndex[class.Name] = ix
 		}
-		mergeClass(&dst.Classes[ix], class)
+		m.mergeClass(&dst.Classes[ix], class)
 	}
 
 	sort.SliceStable(dst.Classes, func(i, j int) bool {
@@ -56,11 +216,21 @@ func mergePackage(dst *Package, src Package) {
 	})
 }
 
-func mergeClass(dst *Class, src Class) {
+func (m merger) mergeClass(dst *Class, src Class) {
 	if dst.SourceFileName == "" {
 		dst.SourceFileName = src.SourceFileName
 	}
-	dst.Counters = mergeCounterSlices(dst.Counters, src.Counters)
+	fresh := len(dst.Counters) == 0 && len(dst.Lines) == 0
+	useLines := m.strategy == MergeUnion && !fresh && linesMatchCounters(*dst) && linesMatchCounters(src)
+	if m.strategy == MergeSum {
+		dst.Lines = mergeLines(dst.Lines, src.Lines, sumLine)
+	} else {
+		dst.Lines = mergeLines(dst.Lines, src.Lines, unionLine)
+	}
+	dst.Counters = m.counters(dst.Counters, src.Counters)
+	if useLines {
+		dst.Counters = replaceLineCounters(dst.Counters, dst.Lines)
+	}
 
 	methodIndex := map[string]int{}
 	for i, method := range dst.Methods {
@@ -75,7 +245,10 @@ func mergeClass(dst *Class, src Class) {
 			ix = len(dst.Methods) - 1
 			methodIndex[key] = ix
 		}
-		dst.Methods[ix].Counters = mergeCounterSlices(dst.Methods[ix].Counters, method.Counters)
+		dst.Methods[ix].Counters = m.counters(dst.Methods[ix].Counters, method.Counters)
+	}
+	if m.strategy != MergeSum && !fresh {
+		dst.Counters = replaceMethodCounters(dst.Counters, dst.Methods)
 	}
 
 	sort.SliceStable(dst.Methods, func(i, j int) bool {
@@ -83,6 +256,13 @@ func mergeClass(dst *Class, src Class) {
 	})
 }
 
+func (m merger) counters(dst []Counter, src []Counter) []Counter {
+	if m.strategy == MergeSum {
+		return mergeCounterSlices(dst, src)
+	}
+	return maxCounterSlices(dst, src)
+}
+
 func mergeCounterSlices(dst []Counter, src []Counter) []Counter {
 	if len(src) == 0 {
 		return dst
@@ -106,6 +286,184 @@ func mergeCounterSlices(dst []Counter, src []Counter) []Counter {
 	return dst
 }
 
+// maxCounterSlices keeps, per counter type, the larger covered count and the
+// larger total, so the same code measured twice is counted once.
+func maxCounterSlices(dst []Counter, src []Counter) []Counter {
+	if len(src) == 0 {
+		return dst
+	}
+	index := map[CounterType]int{}
+	for i, c := range dst {
+		index[c.Type] = i
+	}
+	for _, c := range src {
+		ix, ok := index[c.Type]
+		if !ok {
+			index[c.Type] = len(dst)
+			dst = append(dst, Counter{Type: c.Type, Missed: c.Missed, Covered: c.Covered})
+			continue
+		}
+		covered := max(dst[ix].Covered, c.Covered)
+		total := max(dst[ix].Total(), c.Total(), covered)
+		dst[ix].Covered = covered
+		dst[ix].Missed = total - covered
+	}
+	sort.SliceStable(dst, func(i, j int) bool {
+		return dst[i].Type < dst[j].Type
+	})
+	return dst
+}
+
+func mergeLines(dst []Line, src []Line, combine func(a, b Line) Line) []Line {
+	if len(src) == 0 {
+		return dst
+	}
+	if len(dst) == 0 {
+		return append([]Line(nil), src...)
+	}
+	index := make(map[int]int, len(dst))
+	for i, line := range dst {
+		index[line.Number] = i
+	}
+	for _, line := range src {
+		if ix, ok := index[line.Number]; ok {
+			dst[ix] = combine(dst[ix], line)
+			continue
+		}
+		index[line.Number] = len(dst)
+		dst = append(dst, line)
+	}
+	sortLines(dst)
+	return dst
+}
+
+func sumLine(a, b Line) Line {
+	a.MissedInstructions += b.MissedInstructions
+	a.CoveredInstructions += b.CoveredInstructions
+	a.MissedBranches += b.MissedBranches
+	a.CoveredBranches += b.CoveredBranches
+	return a
+}
+
+// unionLine merges the same line seen by two reports. Reports do not identify
+// individual instructions or branches, so the best coverage of either side
+// stands for their union.
+func unionLine(a, b Line) Line {
+	a.CoveredInstructions, a.MissedInstructions = maxPair(
+		a.CoveredInstructions, a.MissedInstructions, b.CoveredInstructions, b.MissedInstructions)
+	a.CoveredBranches, a.MissedBranches = maxPair(
+		a.CoveredBranches, a.MissedBranches, b.CoveredBranches, b.MissedBranches)
+	return a
+}
+
+func maxPair(coveredA, missedA, coveredB, missedB int) (covered, missed int) {
+	covered = max(coveredA, coveredB)
+	total := max(coveredA+missedA, coveredB+missedB, covered)
+	return covered, total - covered
+}
+
+// lineCounters derives INSTRUCTION, LINE and BRANCH counters from line data,
+// counting only lines that hold instructions as lines.
+func lineCounters(lines []Line) (instruction, line, branch Counter) {
+	instruction = Counter{Type: CounterInstruction}
+	line = Counter{Type: CounterLine}
+	branch = Counter{Type: CounterBranch}
+	for _, l := range lines {
+		instruction.Missed += l.MissedInstructions
+		instruction.Covered += l.CoveredInstructions
+		branch.Missed += l.MissedBranches
+		branch.Covered += l.CoveredBranches
+		switch {
+		case l.Covered():
+			line.Covered++
+		case l.MissedInstructions > 0:
+			line.Missed++
+		}
+	}
+	return instruction, line, branch
+}
+
+// linesMatchCounters reports whether a class's line data agrees with its LINE
+// and BRANCH counters. JaCoCo lines are assigned to classes heuristically, so
+// a mismatch means the lines cannot be trusted for union merging.
+func linesMatchCounters(c Class) bool {
+	if len(c.Lines) == 0 {
+		return false
+	}
+	_, line, branch := lineCounters(c.Lines)
+	if lc, ok := c.Counter(CounterLine); !ok || lc.Total() != line.Total() {
+		return false
+	}
+	bc, _ := c.Counter(CounterBranch)
+	return bc.Total() == branch.Total()
+}
+
+// replaceLineCounters overwrites the LINE and BRANCH counters, and INSTRUCTION
+// when every instruction is attributed to a line, with values from lines.
+func replaceLineCounters(counters []Counter, lines []Line) []Counter {
+	instruction, line, branch := lineCounters(lines)
+	out := make([]Counter, 0, len(counters))
+	for _, c := range counters {
+		switch {
+		case c.Type == CounterLine:
+			c = line
+		case c.Type == CounterBranch:
+			c = branch
+		case c.Type == CounterInstruction && c.Total() == instruction.Total():
+			c = instruction
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// replaceMethodCounters rebuilds the METHOD and COMPLEXITY counters of a class
+// from its merged methods, and its CLASS counter from whether any of them is
+// covered. Taking the larger class counter of each report would count a class
+// whose reports cover different methods as partly missed.
+func replaceMethodCounters(counters []Counter, methods []Method) []Counter {
+	agg := map[CounterType]Counter{}
+	for _, method := range methods {
+		mergeCounters(agg, method.Counters)
+	}
+	out := make([]Counter, 0, len(counters))
+	for _, c := range counters {
+		switch c.Type {
+		case CounterMethod, CounterComplexity:
+			if sum, ok := agg[c.Type]; ok {
+				c = sum
+			}
+		case CounterClass:
+			if sum, ok := agg[CounterMethod]; ok {
+				c = Counter{Type: CounterClass, Missed: 1}
+				if sum.Covered > 0 {
+					c = Counter{Type: CounterClass, Covered: 1}
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func recomputeGroupCounters(groups []Group) {
+	for i := range groups {
+		recomputeGroupCounters(groups[i].Groups)
+		recomputePackageCounters(groups[i].Packages)
+		if len(groups[i].Groups) > 0 || len(groups[i].Packages) > 0 {
+			groups[i].Counters = sumContainerCounters(groups[i].Groups, groups[i].Packages)
+		}
+	}
+}
+
+func recomputePackageCounters(pkgs []Package) {
+	for i := range pkgs {
+		if len(pkgs[i].Classes) > 0 {
+			pkgs[i].Counters = sumClassCounters(pkgs[i].Classes)
+		}
+	}
+}
+
 func methodMergeKey(m Method) string {
 	return m.Name + "\x00" + m.Desc + "\x00" + strconv.Itoa(m.Line)
 }
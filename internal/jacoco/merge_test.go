@@ -83,3 +83,212 @@ func TestMergeReportsKeepsDistinctNodes(t *testing.T) {
 		t.Fatalf("unexpected package ordering: %#v", merged.Packages)
 	}
 }
+
+func TestMergeReportsMergesGroupsByName(t *testing.T) {
+	r1 := Report{Groups: []Group{{
+		Name:     "module-a",
+		Counters: []Counter{{Type: CounterInstruction, Missed: 1, Covered: 1}},
+		Packages: []Package{{Name: "pkg"}},
+	}}}
+	r2 := Report{Groups: []Group{
+		{
+			Name:     "module-a",
+			Counters: []Counter{{Type: CounterInstruction, Missed: 2, Covered: 2}},
+			Packages: []Package{{Name: "pkg"}, {Name: "other"}},
+		},
+		{Name: "module-b"},
+	}}
+
+	merged := MergeReports(r1, r2)
+	if len(merged.Groups) != 2 {
+		t.Fatalf("group count mismatch: %d", len(merged.Groups))
+	}
+	group := merged.Groups[0]
+	if group.Name != "module-a" || len(group.Packages) != 2 {
+		t.Fatalf("unexpected merged group: %#v", group)
+	}
+	gc, ok := group.Counter(CounterInstruction)
+	if !ok || gc.Missed != 3 || gc.Covered != 3 {
+		t.Fatalf("group counter mismatch: %#v", gc)
+	}
+}
+
+func TestGroupReportsKeepsFormatsApart(t *testing.T) {
+	javaReport := Report{
+		Name:     "backend",
+		Inputs:   []Input{{Path: "jacoco.xml", Format: FormatJaCoCo}},
+		Counters: []Counter{{Type: CounterInstruction, Missed: 10, Covered: 90}, {Type: CounterLine, Missed: 2, Covered: 8}},
+		Packages: []Package{{Name: "com/example", Counters: []Counter{{Type: CounterLine, Missed: 2, Covered: 8}}}},
+	}
+	tsReport := Report{
+		Inputs:   []Input{{Path: "lcov.info", Format: FormatLCOV}},
+		Counters: []Counter{{Type: CounterLine, Missed: 1, Covered: 4}, {Type: CounterMethod, Missed: 1, Covered: 1}},
+		Packages: []Package{{Name: "src", Counters: []Counter{{Type: CounterLine, Missed: 1, Covered: 4}}}},
+	}
+
+	grouped := GroupReports(MergeUnion, []Report{javaReport, tsReport}, []string{"jacoco", "lcov"})
+	if len(grouped.Groups) != 2 || grouped.Groups[0].Name != "jacoco" || grouped.Groups[1].Name != "lcov" {
+		t.Fatalf("unexpected groups: %+v", grouped.Groups)
+	}
+	if len(grouped.Inputs) != 2 {
+		t.Fatalf("inputs should be kept: %+v", grouped.Inputs)
+	}
+	if c, ok := grouped.Groups[0].Counter(CounterInstruction); !ok || c.Covered != 90 {
+		t.Fatalf("group counters should keep their format: %+v", grouped.Groups[0].Counters)
+	}
+	line, ok := grouped.Counter(CounterLine)
+	if !ok || line.Missed != 3 || line.Covered != 12 {
+		t.Fatalf("unexpected root line counter: %+v", grouped.Counters)
+	}
+	if _, ok := grouped.Counter(CounterInstruction); ok {
+		t.Fatalf("root should not mix format specific counters: %+v", grouped.Counters)
+	}
+	if _, ok := grouped.Counter(CounterMethod); ok {
+		t.Fatalf("root should not mix format specific counters: %+v", grouped.Counters)
+	}
+}
+
+func TestGroupReportsMergesSameLabel(t *testing.T) {
+	a := Report{
+		Inputs:   []Input{{Path: "a.xml", Format: FormatJaCoCo}},
+		Counters: []Counter{{Type: CounterInstruction, Missed: 1, Covered: 1}},
+	}
+	b := Report{
+		Inputs:   []Input{{Path: "b.xml", Format: FormatJaCoCo}},
+		Counters: []Counter{{Type: CounterInstruction, Missed: 2, Covered: 2}},
+	}
+
+	grouped := GroupReports(MergeSum, []Report{a, b}, []string{"jacoco", "jacoco"})
+	if len(grouped.Groups) != 1 {
+		t.Fatalf("expected one group, got %+v", grouped.Groups)
+	}
+	if c, ok := grouped.Counter(CounterInstruction); !ok || c.Missed != 3 || c.Covered != 3 {
+		t.Fatalf("single format root should keep all counters: %+v", grouped.Counters)
+	}
+}
+
+func lineReport(lines ...Line) Report {
+	instruction, line, branch := lineCounters(lines)
+	counters := []Counter{instruction, line}
+	if branch.Total() > 0 {
+		counters = append(counters, branch)
+	}
+	class := Class{Name: "p/A", SourceFileName: "A.java", Lines: lines, Counters: counters}
+	return Report{
+		Packages: []Package{{Name: "p", Classes: []Class{class}, Counters: counters}},
+		Counters: counters,
+	}
+}
+
+func TestMergeReportsWithUnionCountsLinesOnce(t *testing.T) {
+	unit := lineReport(
+		Line{Number: 1, CoveredInstructions: 2},
+		Line{Number: 2, MissedInstructions: 3},
+		Line{Number: 3, MissedInstructions: 1, MissedBranches: 2},
+	)
+	integration := lineReport(
+		Line{Number: 1, MissedInstructions: 2},
+		Line{Number: 2, CoveredInstructions: 3},
+		Line{Number: 3, MissedInstructions: 1, MissedBranches: 1, CoveredBranches: 1},
+	)
+
+	merged := MergeReportsWith(MergeUnion, unit, integration)
+	class := merged.Packages[0].Classes[0]
+	if line, _ := class.Counter(CounterLine); line.Covered != 2 || line.Missed != 1 {
+		t.Fatalf("line counter mismatch: %#v", line)
+	}
+	if instr, _ := class.Counter(CounterInstruction); instr.Covered != 5 || instr.Missed != 1 {
+		t.Fatalf("instruction counter mismatch: %#v", instr)
+	}
+	if branch, _ := class.Counter(CounterBranch); branch.Covered != 1 || branch.Missed != 1 {
+		t.Fatalf("branch counter mismatch: %#v", branch)
+	}
+	if line, _ := merged.Counter(CounterLine); line.Covered != 2 || line.Missed != 1 {
+		t.Fatalf("report counters should be recomputed from classes: %#v", merged.Counters)
+	}
+
+	summed := MergeReportsWith(MergeSum, unit, integration)
+	if line, _ := summed.Counter(CounterLine); line.Total() != 6 {
+		t.Fatalf("sum should keep double counting: %#v", line)
+	}
+}
+
+func TestMergeReportsWithUnionFallsBackToMaxWithoutLines(t *testing.T) {
+	class := func(missed, covered int) Report {
+		counters := []Counter{{Type: CounterLine, Missed: missed, Covered: covered}}
+		return Report{Packages: []Package{{
+			Name:     "p",
+			Classes:  []Class{{Name: "p/A", Counters: counters, Methods: []Method{{Name: "f", Desc: "()V", Counters: counters}}}},
+			Counters: counters,
+		}}}
+	}
+
+	for _, strategy := range []MergeStrategy{MergeUnion, MergeMax} {
+		merged := MergeReportsWith(strategy, class(6, 4), class(3, 7))
+		got := merged.Packages[0].Classes[0]
+		if line, _ := got.Counter(CounterLine); line.Covered != 7 || line.Missed != 3 {
+			t.Fatalf("%s: class counter mismatch: %#v", strategy, line)
+		}
+		if line, _ := got.Methods[0].Counter(CounterLine); line.Covered != 7 || line.Missed != 3 {
+			t.Fatalf("%s: method counter mismatch: %#v", strategy, line)
+		}
+		if line, _ := merged.Counter(CounterLine); line.Total() != 10 {
+			t.Fatalf("%s: report counter mismatch: %#v", strategy, line)
+		}
+	}
+}
+
+func TestMergeReportsWithUnionKeepsDisjointClassesSummed(t *testing.T) {
+	a := lineReport(Line{Number: 1, CoveredInstructions: 1})
+	b := lineReport(Line{Number: 1, MissedInstructions: 1})
+	b.Packages[0].Classes[0].Name = "p/B"
+
+	merged := MergeReportsWith(MergeUnion, a, b)
+	if line, _ := merged.Counter(CounterLine); line.Covered != 1 || line.Missed != 1 {
+		t.Fatalf("disjoint classes should add up: %#v", line)
+	}
+}
+
+func TestMergeReportsWithUnionRecomputesMethodCounters(t *testing.T) {
+	report := func(covered string) Report {
+		methods := []Method{{Name: "a", Desc: "()V"}, {Name: "b", Desc: "()V"}}
+		for i := range methods {
+			c := Counter{Type: CounterMethod, Missed: 1}
+			if methods[i].Name == covered {
+				c = Counter{Type: CounterMethod, Covered: 1}
+			}
+			methods[i].Counters = []Counter{c, {Type: CounterComplexity, Missed: c.Missed, Covered: c.Covered}}
+		}
+		counters := []Counter{
+			{Type: CounterComplexity, Missed: 1, Covered: 1},
+			{Type: CounterMethod, Missed: 1, Covered: 1},
+			{Type: CounterClass, Covered: 1},
+		}
+		return Report{Packages: []Package{{
+			Name:     "p",
+			Classes:  []Class{{Name: "p/A", Counters: counters, Methods: methods}},
+			Counters: counters,
+		}}}
+	}
+
+	for _, strategy := range []MergeStrategy{MergeUnion, MergeMax} {
+		merged := MergeReportsWith(strategy, report("a"), report("b"))
+		class := merged.Packages[0].Classes[0]
+		for _, method := range class.Methods {
+			if c, _ := method.Counter(CounterMethod); c.Covered != 1 || c.Missed != 0 {
+				t.Fatalf("%s: method %s mismatch: %#v", strategy, method.Name, c)
+			}
+		}
+		for _, typ := range []CounterType{CounterMethod, CounterComplexity} {
+			if c, _ := class.Counter(typ); c.Covered != 2 || c.Missed != 0 {
+				t.Fatalf("%s: class %s mismatch: %#v", strategy, typ, c)
+			}
+			if c, _ := merged.Counter(typ); c.Covered != 2 || c.Missed != 0 {
+				t.Fatalf("%s: report %s mismatch: %#v", strategy, typ, c)
+			}
+		}
+		if c, _ := class.Counter(CounterClass); c.Covered != 1 || c.Missed != 0 {
+			t.Fatalf("%s: class counter mismatch: %#v", strategy, c)
+		}
+	}
+}
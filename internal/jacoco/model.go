@@ -1,6 +1,9 @@
 package jacoco
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // CounterType is a JaCoCo coverage counter category.
 type CounterType string
@@ -28,6 +31,17 @@ type Counter struct {
 	Type    CounterType
 	Missed  int
 	Covered int
+	// Layers holds the share of each merged input, indexed like
+	// Report.Layers. It is nil unless several reports were merged.
+	Layers []LayerCounter
+}
+
+// LayerCounter is what one merged input measured for a counter.
+type LayerCounter struct {
+	Missed  int
+	Covered int
+	// OnlyCovered is the part of Covered that no other input covers.
+	OnlyCovered int
 }
 
 func (c Counter) Total() int {
@@ -50,12 +64,31 @@ type Method struct {
 	Counters []Counter
 }
 
-// Class corresponds to a JaCoCo class node.
+// Line is the coverage of one source line, like JaCoCo's <line> element.
+// Formats that only record hits per line use one instruction per line.
+type Line struct {
+	Number              int
+	MissedInstructions  int
+	CoveredInstructions int
+	MissedBranches      int
+	CoveredBranches     int
+}
+
+// Covered reports whether any instruction on the line was executed.
+func (l Line) Covered() bool {
+	return l.CoveredInstructions > 0
+}
+
+// Class corresponds to a JaCoCo class node. Lines is sorted by number and may
+// be empty when the report carries no line data. Nested is set only in the
+// view built by FoldNestedClasses.
 type Class struct {
 	Name           string
 	SourceFileName string
 	Methods        []Method
+	Lines          []Line
 	Counters       []Counter
+	Nested         []Class
 }
 
 // Package corresponds to a JaCoCo package node.
@@ -65,9 +98,39 @@ type Package struct {
 	Counters []Counter
 }
 
-// Report is the root JaCoCo model.
+// Group corresponds to a JaCoCo group node. Groups appear in aggregate and
+// Ant task reports and may nest to any depth above packages.
+type Group struct {
+	Name     string
+	Groups   []Group
+	Packages []Package
+	Counters []Counter
+}
+
+// SessionInfo corresponds to a JaCoCo sessioninfo entry, one per test JVM
+// whose execution data went into the report.
+type SessionInfo struct {
+	ID    string
+	Start time.Time
+	Dump  time.Time
+}
+
+// Input describes a report file that contributed to a Report.
+type Input struct {
+	Path          string
+	Format        InputFormat
+	ModTime       time.Time
+	ParseDuration time.Duration
+}
+
+// Report is the root JaCoCo model. Layers names the merged inputs whose
+// shares are kept in Counter.Layers.
 type Report struct {
 	Name     string
+	Sessions []SessionInfo
+	Inputs   []Input
+	Layers   []string
+	Groups   []Group
 	Packages []Package
 	Counters []Counter
 }
@@ -84,6 +147,10 @@ func (p Package) Counter(t CounterType) (Counter, bool) {
 	return findCounter(p.Counters, t)
 }
 
+func (g Group) Counter(t CounterType) (Counter, bool) {
+	return findCounter(g.Counters, t)
+}
+
 func (r Report) Counter(t CounterType) (Counter, bool) {
 	return findCounter(r.Counters, t)
 }
@@ -98,10 +165,9 @@ func findCounter(counters []Counter, t CounterType) (Counter, bool) {
 }
 
 func normalizeCounterType(raw string) (CounterType, error) {
-	t := CounterType(raw)
 	for _, supported := range allCounterTypes {
-		if t == supported {
-			return t, nil
+		if raw == string(supported) {
+			return supported, nil
 		}
 	}
 	return "", fmt.Errorf("unsupported counter type: %s", raw)
@@ -4,11 +4,12 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"os"
+	"sort"
+	"time"
 )
 
 func ParseFile(path string) (Report, error) {
-	f, err := os.Open(path)
+	f, err := OpenInput(path)
 	if err != nil {
 		return Report{}, fmt.Errorf("open report: %w", err)
 	}
@@ -17,78 +18,367 @@ func ParseFile(path string) (Report, error) {
 	return Parse(f)
 }
 
+// Parse reads a JaCoCo XML report. The document is streamed token by token
+// and the model is built directly, so memory use stays proportional to the
+// resulting Report rather than to the XML document.
 func Parse(r io.Reader) (Report, error) {
-	var xr xmlReport
-	dec := xml.NewDecoder(r)
-	if err := dec.Decode(&xr); err != nil {
-		return Report{}, fmt.Errorf("decode xml: %w", err)
-	}
+	return jacocoFormat{}.Parse(r)
+}
 
-	report := Report{Name: xr.Name}
+// jacocoParser builds a Report from the token stream. Unless lines is set,
+// the <sourcefile> elements are skipped, since their lines take more memory
+// than the rest of the report and only merging and pragmas read them.
+type jacocoParser struct {
+	s     *xmlStream
+	lines bool
+}
 
-	counters, err := decodeCounters(xr.Counters)
+func (p *jacocoParser) parseReport() (Report, error) {
+	root, err := p.s.root()
 	if err != nil {
-		return Report{}, err
+		return Report{}, fmt.Errorf("decode xml: %w", err)
 	}
-	report.Counters = counters
 
-	for _, xp := range xr.Packages {
-		pkg := Package{Name: xp.Name}
-		pkg.Counters, err = decodeCounters(xp.Counters)
+	report := Report{Name: p.s.attr(root, "name")}
+	for {
+		start, ok, err := p.s.next()
 		if err != nil {
-			return Report{}, err
+			return Report{}, fmt.Errorf("decode xml: %w", err)
 		}
-
-		for _, xc := range xp.Classes {
-			class := Class{Name: xc.Name, SourceFileName: xc.SourceFileName}
-			class.Counters, err = decodeCounters(xc.Counters)
+		if !ok {
+			break
+		}
+		switch start.Name.Local {
+		case "sessioninfo":
+			session, err := p.parseSessionInfo(start)
 			if err != nil {
 				return Report{}, err
 			}
-
-			for _, xm := range xc.Methods {
-				method := Method{Name: xm.Name, Desc: xm.Desc, Line: xm.Line}
-				method.Counters, err = decodeCounters(xm.Counters)
-				if err != nil {
-					return Report{}, err
-				}
-				class.Methods = append(class.Methods, method)
+			report.Sessions = append(report.Sessions, session)
+		case "group":
+			group, err := p.parseGroup(start)
+			if err != nil {
+				return Report{}, err
+			}
+			report.Groups = append(report.Groups, group)
+		case "package":
+			pkg, err := p.parsePackage(start)
+			if err != nil {
+				return Report{}, err
 			}
+			report.Packages = append(report.Packages, pkg)
+		case "counter":
+			counter, err := p.parseCounter(start)
+			if err != nil {
+				return Report{}, err
+			}
+			report.Counters = append(report.Counters, counter)
+		default:
+			if err := p.s.skip(); err != nil {
+				return Report{}, fmt.Errorf("decode xml: %w", err)
+			}
+		}
+	}
 
-			if len(class.Counters) == 0 {
-				class.Counters = sumMethodCounters(class.Methods)
+	if len(report.Counters) == 0 {
+		report.Counters = sumContainerCounters(report.Groups, report.Packages)
+	}
+	return report, nil
+}
+
+func (p *jacocoParser) parseSessionInfo(start xml.StartElement) (SessionInfo, error) {
+	startMillis, err := p.s.int64Attr(start, "start")
+	if err != nil {
+		return SessionInfo{}, fmt.Errorf("decode xml: %w", err)
+	}
+	dumpMillis, err := p.s.int64Attr(start, "dump")
+	if err != nil {
+		return SessionInfo{}, fmt.Errorf("decode xml: %w", err)
+	}
+	if err := p.s.skip(); err != nil {
+		return SessionInfo{}, fmt.Errorf("decode xml: %w", err)
+	}
+	return SessionInfo{
+		ID:    p.s.attr(start, "id"),
+		Start: time.UnixMilli(startMillis),
+		Dump:  time.UnixMilli(dumpMillis),
+	}, nil
+}
+
+func (p *jacocoParser) parseGroup(start xml.StartElement) (Group, error) {
+	group := Group{Name: p.s.attr(start, "name")}
+	for {
+		child, ok, err := p.s.next()
+		if err != nil {
+			return Group{}, fmt.Errorf("decode xml: %w", err)
+		}
+		if !ok {
+			break
+		}
+		switch child.Name.Local {
+		case "group":
+			sub, err := p.parseGroup(child)
+			if err != nil {
+				return Group{}, err
+			}
+			group.Groups = append(group.Groups, sub)
+		case "package":
+			pkg, err := p.parsePackage(child)
+			if err != nil {
+				return Group{}, err
 			}
+			group.Packages = append(group.Packages, pkg)
+		case "counter":
+			counter, err := p.parseCounter(child)
+			if err != nil {
+				return Group{}, err
+			}
+			group.Counters = append(group.Counters, counter)
+		default:
+			if err := p.s.skip(); err != nil {
+				return Group{}, fmt.Errorf("decode xml: %w", err)
+			}
+		}
+	}
+
+	if len(group.Counters) == 0 {
+		group.Counters = sumContainerCounters(group.Groups, group.Packages)
+	}
+	return group, nil
+}
 
+func (p *jacocoParser) parsePackage(start xml.StartElement) (Package, error) {
+	pkg := Package{Name: p.s.internAttr(start, "name")}
+	var sourceLines map[string][]Line
+	for {
+		child, ok, err := p.s.next()
+		if err != nil {
+			return Package{}, fmt.Errorf("decode xml: %w", err)
+		}
+		if !ok {
+			break
+		}
+		switch child.Name.Local {
+		case "class":
+			class, err := p.parseClass(child)
+			if err != nil {
+				return Package{}, err
+			}
 			pkg.Classes = append(pkg.Classes, class)
+		case "sourcefile":
+			if !p.lines {
+				if err := p.s.skip(); err != nil {
+					return Package{}, fmt.Errorf("decode xml: %w", err)
+				}
+				continue
+			}
+			name := p.s.attr(child, "name")
+			lines, err := p.parseSourceFile()
+			if err != nil {
+				return Package{}, err
+			}
+			if sourceLines == nil {
+				sourceLines = map[string][]Line{}
+			}
+			sourceLines[name] = append(sourceLines[name], lines...)
+		case "counter":
+			counter, err := p.parseCounter(child)
+			if err != nil {
+				return Package{}, err
+			}
+			pkg.Counters = append(pkg.Counters, counter)
+		default:
+			if err := p.s.skip(); err != nil {
+				return Package{}, fmt.Errorf("decode xml: %w", err)
+			}
 		}
+	}
 
-		if len(pkg.Counters) == 0 {
-			pkg.Counters = sumClassCounters(pkg.Classes)
+	assignSourceLines(pkg.Classes, sourceLines)
+	if len(pkg.Counters) == 0 {
+		pkg.Counters = sumClassCounters(pkg.Classes)
+	}
+	return pkg, nil
+}
+
+// parseSourceFile reads the <line> children of a <sourcefile> element.
+func (p *jacocoParser) parseSourceFile() ([]Line, error) {
+	var lines []Line
+	for {
+		child, ok, err := p.s.next()
+		if err != nil {
+			return nil, fmt.Errorf("decode xml: %w", err)
+		}
+		if !ok {
+			return lines, nil
+		}
+		if child.Name.Local != "line" {
+			if err := p.s.skip(); err != nil {
+				return nil, fmt.Errorf("decode xml: %w", err)
+			}
+			continue
+		}
+		var line Line
+		for _, field := range []struct {
+			attr string
+			dst  *int
+		}{
+			{"nr", &line.Number},
+			{"mi", &line.MissedInstructions},
+			{"ci", &line.CoveredInstructions},
+			{"mb", &line.MissedBranches},
+			{"cb", &line.CoveredBranches},
+		} {
+			if *field.dst, err = p.s.intAttr(child, field.attr); err != nil {
+				return nil, fmt.Errorf("decode xml: %w", err)
+			}
 		}
+		if err := p.s.skip(); err != nil {
+			return nil, fmt.Errorf("decode xml: %w", err)
+		}
+		lines = append(lines, line)
+	}
+}
 
-		report.Packages = append(report.Packages, pkg)
+// assignSourceLines distributes the lines of each source file to the classes
+// compiled from it. JaCoCo reports lines per source file only, so a line goes
+// to the class owning the closest method that starts at or before it; lines
+// above the first method go to the class of that method.
+func assignSourceLines(classes []Class, sourceLines map[string][]Line) {
+	if len(sourceLines) == 0 {
+		return
+	}
+	type methodStart struct {
+		line  int
+		class int
+	}
+	starts := map[string][]methodStart{}
+	for i, class := range classes {
+		for _, method := range class.Methods {
+			if method.Line > 0 {
+				starts[class.SourceFileName] = append(starts[class.SourceFileName], methodStart{line: method.Line, class: i})
+			}
+		}
 	}
+	for file, lines := range sourceLines {
+		fileStarts := starts[file]
+		if len(fileStarts) == 0 {
+			continue
+		}
+		sort.SliceStable(fileStarts, func(i, j int) bool {
+			return fileStarts[i].line < fileStarts[j].line
+		})
+		for _, line := range lines {
+			owner := fileStarts[0].class
+			ix := sort.Search(len(fileStarts), func(i int) bool {
+				return fileStarts[i].line > line.Number
+			})
+			if ix > 0 {
+				owner = fileStarts[ix-1].class
+			}
+			classes[owner].Lines = append(classes[owner].Lines, line)
+		}
+	}
+	for i := range classes {
+		sortLines(classes[i].Lines)
+	}
+}
 
-	if len(report.Counters) == 0 {
-		report.Counters = sumPackageCounters(report.Packages)
+func sortLines(lines []Line) {
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].Number < lines[j].Number
+	})
+}
+
+func (p *jacocoParser) parseClass(start xml.StartElement) (Class, error) {
+	class := Class{
+		Name:           p.s.attr(start, "name"),
+		SourceFileName: p.s.internAttr(start, "sourcefilename"),
+	}
+	for {
+		child, ok, err := p.s.next()
+		if err != nil {
+			return Class{}, fmt.Errorf("decode xml: %w", err)
+		}
+		if !ok {
+			break
+		}
+		switch child.Name.Local {
+		case "method":
+			method, err := p.parseMethod(child)
+			if err != nil {
+				return Class{}, err
+			}
+			class.Methods = append(class.Methods, method)
+		case "counter":
+			counter, err := p.parseCounter(child)
+			if err != nil {
+				return Class{}, err
+			}
+			class.Counters = append(class.Counters, counter)
+		default:
+			if err := p.s.skip(); err != nil {
+				return Class{}, fmt.Errorf("decode xml: %w", err)
+			}
+		}
 	}
 
-	return report, nil
+	if len(class.Counters) == 0 {
+		class.Counters = sumMethodCounters(class.Methods)
+	}
+	return class, nil
 }
 
-func decodeCounters(raw []xmlCounter) ([]Counter, error) {
-	if len(raw) == 0 {
-		return nil, nil
+func (p *jacocoParser) parseMethod(start xml.StartElement) (Method, error) {
+	line, err := p.s.intAttr(start, "line")
+	if err != nil {
+		return Method{}, fmt.Errorf("decode xml: %w", err)
+	}
+	method := Method{
+		Name: p.s.internAttr(start, "name"),
+		Desc: p.s.internAttr(start, "desc"),
+		Line: line,
 	}
-	out := make([]Counter, 0, len(raw))
-	for _, rc := range raw {
-		t, err := normalizeCounterType(rc.Type)
+	for {
+		child, ok, err := p.s.next()
 		if err != nil {
-			return nil, err
+			return Method{}, fmt.Errorf("decode xml: %w", err)
 		}
-		out = append(out, Counter{Type: t, Missed: rc.Missed, Covered: rc.Covered})
+		if !ok {
+			break
+		}
+		if child.Name.Local != "counter" {
+			if err := p.s.skip(); err != nil {
+				return Method{}, fmt.Errorf("decode xml: %w", err)
+			}
+			continue
+		}
+		counter, err := p.parseCounter(child)
+		if err != nil {
+			return Method{}, err
+		}
+		method.Counters = append(method.Counters, counter)
+	}
+	return method, nil
+}
+
+func (p *jacocoParser) parseCounter(start xml.StartElement) (Counter, error) {
+	t, err := normalizeCounterType(p.s.attr(start, "type"))
+	if err != nil {
+		return Counter{}, err
+	}
+	missed, err := p.s.intAttr(start, "missed")
+	if err != nil {
+		return Counter{}, fmt.Errorf("decode xml: %w", err)
 	}
-	return out, nil
+	covered, err := p.s.intAttr(start, "covered")
+	if err != nil {
+		return Counter{}, fmt.Errorf("decode xml: %w", err)
+	}
+	if err := p.s.skip(); err != nil {
+		return Counter{}, fmt.Errorf("decode xml: %w", err)
+	}
+	return Counter{Type: t, Missed: missed, Covered: covered}, nil
 }
 
 func sumMethodCounters(methods []Method) []Counter {
@@ -115,6 +405,19 @@ func sumPackageCounters(pkgs []Package) []Counter {
 	return mapToCounters(agg)
 }
 
+// sumContainerCounters aggregates the counters of the direct child groups and
+// packages of a report or group.
+func sumContainerCounters(groups []Group, pkgs []Package) []Counter {
+	agg := map[CounterType]Counter{}
+	for _, g := range groups {
+		mergeCounters(agg, g.Counters)
+	}
+	for _, p := range pkgs {
+		mergeCounters(agg, p.Counters)
+	}
+	return mapToCounters(agg)
+}
+
 func mergeCounters(agg map[CounterType]Counter, counters []Counter) {
 	for _, c := range counters {
 		base := agg[c.Type]
@@ -103,3 +103,145 @@ func TestParseRejectsUnknownCounterType(t *testing.T) {
 		t.Fatal("expected error for unknown counter type")
 	}
 }
+
+func TestParseNestedGroups(t *testing.T) {
+	xmlText := `
+<report name="aggregate">
+  <group name="module-a">
+    <group name="sub">
+      <package name="com/example/a">
+        <class name="com/example/a/A" sourcefilename="A.java">
+          <method name="run" desc="()V" line="3">
+            <counter type="INSTRUCTION" missed="1" covered="3"/>
+          </method>
+        </class>
+      </package>
+    </group>
+  </group>
+  <group name="module-b">
+    <package name="com/example/b">
+      <class name="com/example/b/B" sourcefilename="B.java">
+        <counter type="INSTRUCTION" missed="2" covered="2"/>
+      </class>
+    </package>
+  </group>
+</report>`
+
+	report, err := Parse(strings.NewReader(xmlText))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(report.Groups) != 2 || len(report.Packages) != 0 {
+		t.Fatalf("unexpected top level: groups=%d packages=%d", len(report.Groups), len(report.Packages))
+	}
+
+	moduleA := report.Groups[0]
+	if moduleA.Name != "module-a" || len(moduleA.Groups) != 1 {
+		t.Fatalf("unexpected module-a group: %#v", moduleA)
+	}
+	sub := moduleA.Groups[0]
+	if len(sub.Packages) != 1 || sub.Packages[0].Name != "com/example/a" {
+		t.Fatalf("nested group packages mismatch: %#v", sub.Packages)
+	}
+	gc, ok := moduleA.Counter(CounterInstruction)
+	if !ok || gc.Missed != 1 || gc.Covered != 3 {
+		t.Fatalf("group aggregate mismatch: %#v", gc)
+	}
+
+	rc, ok := report.Counter(CounterInstruction)
+	if !ok || rc.Missed != 3 || rc.Covered != 5 {
+		t.Fatalf("report aggregate mismatch: %#v", rc)
+	}
+}
+
+func TestParseSessionInfo(t *testing.T) {
+	xmlText := `
+<report name="demo">
+  <sessioninfo id="unit-1" start="1700000000000" dump="1700000005000"/>
+  <sessioninfo id="it-1" start="1700000100000" dump="1700000200000"/>
+</report>`
+
+	report, err := Parse(strings.NewReader(xmlText))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(report.Sessions) != 2 {
+		t.Fatalf("session count mismatch: %d", len(report.Sessions))
+	}
+	s := report.Sessions[0]
+	if s.ID != "unit-1" || s.Start.UnixMilli() != 1700000000000 || s.Dump.UnixMilli() != 1700000005000 {
+		t.Fatalf("unexpected session: %#v", s)
+	}
+}
+
+func TestParseSkipsUnknownElementsAndDoctype(t *testing.T) {
+	xmlText := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">
+<report name="demo">
+  <package name="p">
+    <class name="p/A" sourcefilename="A.java">
+      <method name="a" desc="()V" line="1"><counter type="LINE" missed="0" covered="2"/></method>
+    </class>
+    <sourcefile name="A.java"><line nr="1" mi="0" ci="2" mb="0" cb="0"/></sourcefile>
+  </package>
+</report>`
+
+	report, err := Parse(strings.NewReader(xmlText))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	rc, ok := report.Counter(CounterLine)
+	if !ok || rc.Covered != 2 {
+		t.Fatalf("report line counter mismatch: %#v", rc)
+	}
+}
+
+func TestParseRejectsTruncatedXML(t *testing.T) {
+	_, err := Parse(strings.NewReader(`<report name="demo"><package name="p">`))
+	if err == nil {
+		t.Fatal("expected error for truncated xml")
+	}
+}
+
+func TestParseRejectsInvalidCounterValue(t *testing.T) {
+	_, err := Parse(strings.NewReader(`<report><counter type="LINE" missed="x" covered="1"/></report>`))
+	if err == nil {
+		t.Fatal("expected error for non-numeric counter value")
+	}
+}
+
+func TestParseAssignsSourceLinesToClasses(t *testing.T) {
+	xmlText := `<report name="demo">
+  <package name="p">
+    <class name="p/Outer" sourcefilename="Outer.java">
+      <method name="a" desc="()V" line="3"/>
+      <method name="c" desc="()V" line="20"/>
+    </class>
+    <class name="p/Outer$Inner" sourcefilename="Outer.java">
+      <method name="b" desc="()V" line="10"/>
+    </class>
+    <sourcefile name="Outer.java">
+      <line nr="1" mi="1" ci="0" mb="0" cb="0"/>
+      <line nr="4" mi="0" ci="3" mb="1" cb="1"/>
+      <line nr="11" mi="2" ci="0" mb="0" cb="0"/>
+      <line nr="21" mi="0" ci="1" mb="0" cb="0"/>
+    </sourcefile>
+  </package>
+</report>`
+
+	report, err := Parse(strings.NewReader(xmlText))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	outer := report.Packages[0].Classes[0]
+	inner := report.Packages[0].Classes[1]
+	if len(outer.Lines) != 3 || outer.Lines[0].Number != 1 || outer.Lines[2].Number != 21 {
+		t.Fatalf("outer lines mismatch: %#v", outer.Lines)
+	}
+	if outer.Lines[1].CoveredBranches != 1 || outer.Lines[1].MissedBranches != 1 {
+		t.Fatalf("branch data mismatch: %#v", outer.Lines[1])
+	}
+	if len(inner.Lines) != 1 || inner.Lines[0].Number != 11 || inner.Lines[0].MissedInstructions != 2 {
+		t.Fatalf("inner lines mismatch: %#v", inner.Lines)
+	}
+}
@@ -2,6 +2,7 @@ package jacoco
 
 import (
 	"fmt"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -37,3 +38,57 @@ func TestParsePerformance1000Classes(t *testing.T) {
 		t.Fatalf("parse took too long: %s", elapsed)
 	}
 }
+
+func buildLargeJaCoCoXML(classes int) string {
+	var b strings.Builder
+	b.WriteString("<report name=\"perf\">")
+	b.WriteString("<sessioninfo id=\"perf\" start=\"1700000000000\" dump=\"1700000001000\"/>")
+	for i := range classes {
+		if i%100 == 0 {
+			if i > 0 {
+				b.WriteString("</package>")
+			}
+			fmt.Fprintf(&b, "<package name=\"com/example/p%d\">", i/100)
+		}
+		fmt.Fprintf(&b, "<class name=\"com/example/p%d/C%d\" sourcefilename=\"C%d.java\">", i/100, i, i)
+		b.WriteString("<method name=\"&lt;init&gt;\" desc=\"()V\" line=\"1\">")
+		b.WriteString("<counter type=\"INSTRUCTION\" missed=\"0\" covered=\"3\"/><counter type=\"LINE\" missed=\"0\" covered=\"1\"/>")
+		b.WriteString("</method>")
+		b.WriteString("<method name=\"run\" desc=\"(Ljava/lang/String;I)V\" line=\"5\">")
+		b.WriteString("<counter type=\"INSTRUCTION\" missed=\"1\" covered=\"9\"/><counter type=\"LINE\" missed=\"1\" covered=\"3\"/>")
+		b.WriteString("</method>")
+		b.WriteString("<counter type=\"INSTRUCTION\" missed=\"1\" covered=\"12\"/><counter type=\"LINE\" missed=\"1\" covered=\"4\"/>")
+		b.WriteString("</class>")
+	}
+	b.WriteString("</package></report>")
+	return b.String()
+}
+
+func BenchmarkParse100kClasses(b *testing.B) {
+	doc := buildLargeJaCoCoXML(100_000)
+	b.SetBytes(int64(len(doc)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	var retained uint64
+	for range b.N {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		report, err := Parse(strings.NewReader(doc))
+		if err != nil {
+			b.Fatalf("parse failed: %v", err)
+		}
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		if len(report.Packages) != 1000 {
+			b.Fatalf("unexpected package count: %d", len(report.Packages))
+		}
+		if after.HeapAlloc > before.HeapAlloc {
+			retained += after.HeapAlloc - before.HeapAlloc
+		}
+	}
+	// retained-MB is the live heap held by the parsed Report.
+	b.ReportMetric(float64(retained)/float64(b.N)/(1<<20), "retained-MB")
+}
@@ -0,0 +1,318 @@
+package jacoco
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExternalParserPrefix is the executable name prefix of external format
+// plugins. A plugin named crv-format-foo provides the format "foo".
+//
+// Plugins are invoked with a single argument:
+//
+//	crv-format-foo detect   stdin: report prefix, stdout: high | low | none
+//	crv-format-foo parse    stdin: whole report,  stdout: report JSON
+//
+// The JSON document mirrors Report; see pluginReport for the field names.
+// Counters missing on a node are summed from its children.
+const ExternalParserPrefix = "crv-format-"
+
+// pluginDetectTimeout bounds a detect call so that a broken plugin cannot
+// stall format detection.
+const pluginDetectTimeout = 5 * time.Second
+
+// pluginParseTimeout bounds a parse call. It is longer than the detect timeout
+// since the plugin reads the whole report.
+var pluginParseTimeout = 2 * time.Minute
+
+// DiscoverExternalParsers scans the directories of pathEnv (formatted like
+// $PATH) for plugin executables. The first executable found for a name wins,
+// the same way the shell resolves commands.
+func DiscoverExternalParsers(pathEnv string) map[string]string {
+	found := map[string]string{}
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginFormatName(entry.Name())
+			if !ok {
+				continue
+			}
+			if _, exists := found[name]; exists {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutableFile(path) {
+				continue
+			}
+			found[name] = path
+		}
+	}
+	return found
+}
+
+// RegisterExternalParsers registers every plugin found on pathEnv whose name
+// is not already taken, so compiled-in parsers always win.
+func RegisterExternalParsers(pathEnv string) {
+	plugins := DiscoverExternalParsers(pathEnv)
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, exists := LookupParser(name); exists {
+			continue
+		}
+		RegisterParser(name, externalParser{path: plugins[name]})
+	}
+}
+
+var registerPathParsersOnce sync.Once
+
+// RegisterPathParsers registers the plugins found on $PATH, scanning it only on
+// the first call. Callers invoke it when a format has to be detected or is not
+// compiled in, so runs with a built-in --format never scan $PATH.
+func RegisterPathParsers() {
+	registerPathParsersOnce.Do(func() { RegisterExternalParsers(os.Getenv("PATH")) })
+}
+
+func pluginFormatName(file string) (string, bool) {
+	if !strings.HasPrefix(file, ExternalParserPrefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(file, ExternalParserPrefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, ".exe")
+	}
+	if name == "" || name == string(FormatAuto) {
+		return "", false
+	}
+	return strings.ToLower(name), true
+}
+
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+type externalParser struct {
+	path string
+}
+
+func (p externalParser) Detect(prefix []byte) Confidence {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginDetectTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, p.path, "detect")
+	cmd.Stdin = bytes.NewReader(prefix)
+	out, err := cmd.Output()
+	if err != nil {
+		return ConfidenceNone
+	}
+	switch strings.ToLower(strings.TrimSpace(string(out))) {
+	case "high":
+		return ConfidenceHigh
+	case "low":
+		return ConfidenceLow
+	default:
+		return ConfidenceNone
+	}
+}
+
+func (p externalParser) Parse(r io.Reader) (Report, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginParseTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, p.path, "parse")
+	cmd.Stdin = r
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return Report{}, fmt.Errorf("plugin %s: %w", filepath.Base(p.path), err)
+	}
+	if err := cmd.Start(); err != nil {
+		return Report{}, fmt.Errorf("plugin %s: %w", filepath.Base(p.path), err)
+	}
+
+	var pr pluginReport
+	decodeErr := json.NewDecoder(stdout).Decode(&pr)
+	// Drain the rest so the plugin is never blocked writing to a full pipe.
+	_, _ = io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return Report{}, fmt.Errorf("plugin %s: parse timed out after %s", filepath.Base(p.path), pluginParseTimeout)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return Report{}, fmt.Errorf("plugin %s: %w: %s", filepath.Base(p.path), err, msg)
+		}
+		return Report{}, fmt.Errorf("plugin %s: %w", filepath.Base(p.path), err)
+	}
+	if decodeErr != nil {
+		return Report{}, fmt.Errorf("plugin %s: decode json: %w", filepath.Base(p.path), decodeErr)
+	}
+	return pr.toReport()
+}
+
+type pluginReport struct {
+	Name     string          `json:"name"`
+	Groups   []pluginGroup   `json:"groups"`
+	Packages []pluginPackage `json:"packages"`
+	Counters []pluginCounter `json:"counters"`
+}
+
+type pluginGroup struct {
+	Name     string          `json:"name"`
+	Groups   []pluginGroup   `json:"groups"`
+	Packages []pluginPackage `json:"packages"`
+	Counters []pluginCounter `json:"counters"`
+}
+
+type pluginPackage struct {
+	Name     string          `json:"name"`
+	Classes  []pluginClass   `json:"classes"`
+	Counters []pluginCounter `json:"counters"`
+}
+
+type pluginClass struct {
+	Name       string          `json:"name"`
+	SourceFile string          `json:"sourceFile"`
+	Methods    []pluginMethod  `json:"methods"`
+	Lines      []pluginLine    `json:"lines"`
+	Counters   []pluginCounter `json:"counters"`
+}
+
+type pluginLine struct {
+	Number              int `json:"nr"`
+	MissedInstructions  int `json:"mi"`
+	CoveredInstructions int `json:"ci"`
+	MissedBranches      int `json:"mb"`
+	CoveredBranches     int `json:"cb"`
+}
+
+type pluginMethod struct {
+	Name     string          `json:"name"`
+	Desc     string          `json:"desc"`
+	Line     int             `json:"line"`
+	Counters []pluginCounter `json:"counters"`
+}
+
+type pluginCounter struct {
+	Type    string `json:"type"`
+	Missed  int    `json:"missed"`
+	Covered int    `json:"covered"`
+}
+
+func (pr pluginReport) toReport() (Report, error) {
+	report := Report{Name: pr.Name}
+	var err error
+	if report.Counters, err = pluginCounters(pr.Counters); err != nil {
+		return Report{}, err
+	}
+	if report.Groups, err = pluginGroups(pr.Groups); err != nil {
+		return Report{}, err
+	}
+	if report.Packages, err = pluginPackages(pr.Packages); err != nil {
+		return Report{}, err
+	}
+	if len(report.Counters) == 0 {
+		report.Counters = sumContainerCounters(report.Groups, report.Packages)
+	}
+	return report, nil
+}
+
+func pluginGroups(raw []pluginGroup) ([]Group, error) {
+	groups := make([]Group, 0, len(raw))
+	for _, pg := range raw {
+		group := Group{Name: pg.Name}
+		var err error
+		if group.Counters, err = pluginCounters(pg.Counters); err != nil {
+			return nil, err
+		}
+		if group.Groups, err = pluginGroups(pg.Groups); err != nil {
+			return nil, err
+		}
+		if group.Packages, err = pluginPackages(pg.Packages); err != nil {
+			return nil, err
+		}
+		if len(group.Counters) == 0 {
+			group.Counters = sumContainerCounters(group.Groups, group.Packages)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func pluginPackages(raw []pluginPackage) ([]Package, error) {
+	pkgs := make([]Package, 0, len(raw))
+	for _, pp := range raw {
+		pkg := Package{Name: pp.Name}
+		var err error
+		if pkg.Counters, err = pluginCounters(pp.Counters); err != nil {
+			return nil, err
+		}
+		for _, pc := range pp.Classes {
+			class := Class{Name: pc.Name, SourceFileName: pc.SourceFile}
+			if class.Counters, err = pluginCounters(pc.Counters); err != nil {
+				return nil, err
+			}
+			for _, pm := range pc.Methods {
+				method := Method{Name: pm.Name, Desc: pm.Desc, Line: pm.Line}
+				if method.Counters, err = pluginCounters(pm.Counters); err != nil {
+					return nil, err
+				}
+				class.Methods = append(class.Methods, method)
+			}
+			for _, pl := range pc.Lines {
+				class.Lines = append(class.Lines, Line(pl))
+			}
+			sortLines(class.Lines)
+			if len(class.Counters) == 0 {
+				class.Counters = sumMethodCounters(class.Methods)
+			}
+			pkg.Classes = append(pkg.Classes, class)
+		}
+		if len(pkg.Counters) == 0 {
+			pkg.Counters = sumClassCounters(pkg.Classes)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+func pluginCounters(raw []pluginCounter) ([]Counter, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	out := make([]Counter, 0, len(raw))
+	for _, pc := range raw {
+		t, err := normalizeCounterType(strings.ToUpper(pc.Type))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Counter{Type: t, Missed: pc.Missed, Covered: pc.Covered})
+	}
+	return out, nil
+}
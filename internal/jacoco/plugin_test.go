@@ -0,0 +1,128 @@
+package jacoco
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const toyPluginScript = `#!/bin/sh
+case "$1" in
+detect)
+	if head -c 8 | grep -q '^EMBEDDED'; then echo high; else echo none; fi
+	;;
+parse)
+	cat >/dev/null
+	cat <<'JSON'
+{"name":"embedded","packages":[{"name":"fw/drivers","classes":[{"name":"uart.c","sourceFile":"fw/drivers/uart.c","methods":[{"name":"uart_init","line":12,"counters":[{"type":"LINE","missed":1,"covered":4}]}],"lines":[{"nr":13,"ci":2},{"nr":12,"mi":1}]}]}]}
+JSON
+	;;
+*)
+	exit 2
+	;;
+esac
+`
+
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+	path := filepath.Join(dir, ExternalParserPrefix+name)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write plugin failed: %v", err)
+	}
+	return path
+}
+
+func TestDiscoverExternalParsersPrefersEarlierPathEntries(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	want := writePlugin(t, first, "toy", toyPluginScript)
+	writePlugin(t, second, "toy", toyPluginScript)
+	writePlugin(t, second, "other", toyPluginScript)
+	if err := os.WriteFile(filepath.Join(second, ExternalParserPrefix+"noexec"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	found := DiscoverExternalParsers(strings.Join([]string{first, second}, string(os.PathListSeparator)))
+	if len(found) != 2 {
+		t.Fatalf("unexpected plugins: %v", found)
+	}
+	if found["toy"] != want {
+		t.Fatalf("toy plugin should resolve to first PATH entry: %s", found["toy"])
+	}
+}
+
+func TestExternalParserDetectsAndParses(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "embedded", toyPluginScript)
+	restoreRegistryAfter(t)
+	RegisterExternalParsers(dir)
+
+	if _, ok := LookupParser("embedded"); !ok {
+		t.Fatalf("plugin should be registered, got %v", ParserNames())
+	}
+
+	path := filepath.Join(t.TempDir(), "fw.cov")
+	if err := os.WriteFile(path, []byte("EMBEDDED COVERAGE v3\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	report, err := ParseWithFormatFile(path, FormatAuto, ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if report.Name != "embedded" || report.Inputs[0].Format != "embedded" {
+		t.Fatalf("unexpected report: name=%s format=%s", report.Name, report.Inputs[0].Format)
+	}
+	rc, ok := report.Counter(CounterLine)
+	if !ok || rc.Missed != 1 || rc.Covered != 4 {
+		t.Fatalf("counters should be aggregated upward: %#v", rc)
+	}
+	lines := report.Packages[0].Classes[0].Lines
+	if len(lines) != 2 || lines[0].Number != 12 || lines[1].CoveredInstructions != 2 {
+		t.Fatalf("plugin lines mismatch: %#v", lines)
+	}
+}
+
+func TestExternalParserDoesNotShadowBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "jacoco", toyPluginScript)
+	restoreRegistryAfter(t)
+	RegisterExternalParsers(dir)
+
+	p, _ := LookupParser(string(FormatJaCoCo))
+	if _, ok := p.(jacocoFormat); !ok {
+		t.Fatalf("builtin jacoco parser should win, got %T", p)
+	}
+}
+
+func TestExternalParserParseTimesOut(t *testing.T) {
+	dir := t.TempDir()
+	path := writePlugin(t, dir, "slow", "#!/bin/sh\nexec sleep 10\n")
+	restore := pluginParseTimeout
+	pluginParseTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { pluginParseTimeout = restore })
+
+	started := time.Now()
+	_, err := externalParser{path: path}.Parse(strings.NewReader("data"))
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("parse should stop at the timeout, took %s", elapsed)
+	}
+}
+
+func TestExternalParserReportsPluginFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := writePlugin(t, dir, "broken", "#!/bin/sh\necho 'unsupported toolchain' >&2\nexit 3\n")
+
+	_, err := externalParser{path: path}.Parse(strings.NewReader("data"))
+	if err == nil || !strings.Contains(err.Error(), "unsupported toolchain") {
+		t.Fatalf("expected plugin stderr in error, got %v", err)
+	}
+}
@@ -0,0 +1,395 @@
+package jacoco
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Source pragmas that exclude code from coverage. A crv:ignore or
+// "pragma: no cover" comment, matched in any case as coverage.py does,
+// excludes the line it is on, or the next line when it stands alone, together
+// with the block that line opens. @Generated excludes the annotated
+// declaration, and crv:ignore-start / crv:ignore-end exclude everything in
+// between.
+const (
+	pragmaIgnore      = "crv:ignore"
+	pragmaIgnoreStart = "crv:ignore-start"
+	pragmaIgnoreEnd   = "crv:ignore-end"
+	annotationGen     = "Generated"
+)
+
+var pragmaNoCoverRe = regexp.MustCompile(`(?i)pragma:?\s*no\s*cover`)
+
+// hashCommentExts are the extensions of sources whose comments start with #.
+// Elsewhere # begins code such as #include or #[derive].
+var hashCommentExts = map[string]bool{
+	".py": true, ".pyx": true, ".sh": true, ".bash": true, ".rb": true, ".pl": true,
+	".r": true, ".ex": true, ".exs": true, ".cr": true, ".nim": true, ".jl": true,
+}
+
+// ApplySourcePragmas reads the source file of each class below roots and
+// removes the lines and methods excluded by pragmas, recomputing the counters
+// of classes, packages, groups and the report. Classes whose source cannot be
+// found are kept as they are.
+func ApplySourcePragmas(r Report, roots []string) Report {
+	if len(roots) == 0 {
+		return r
+	}
+	a := pragmaApplier{roots: roots, cache: map[string]map[int]bool{}}
+	r.Groups = a.groups(r.Groups)
+	r.Packages = a.packages(r.Packages)
+	if a.changed {
+		r.Counters = sumContainerCounters(r.Groups, r.Packages)
+	}
+	return r
+}
+
+type pragmaApplier struct {
+	roots   []string
+	cache   map[string]map[int]bool
+	changed bool
+}
+
+func (a *pragmaApplier) groups(groups []Group) []Group {
+	out := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		before := a.changed
+		a.changed = false
+		g.Groups = a.groups(g.Groups)
+		g.Packages = a.packages(g.Packages)
+		if a.changed {
+			if len(g.Groups) == 0 && len(g.Packages) == 0 {
+				continue
+			}
+			g.Counters = sumContainerCounters(g.Groups, g.Packages)
+		}
+		a.changed = a.changed || before
+		out = append(out, g)
+	}
+	return out
+}
+
+func (a *pragmaApplier) packages(pkgs []Package) []Package {
+	out := make([]Package, 0, len(pkgs))
+	for _, p := range pkgs {
+		changed := false
+		classes := make([]Class, 0, len(p.Classes))
+		for _, c := range p.Classes {
+			ignored := a.ignoredLines(p.Name, c.SourceFileName)
+			if len(ignored) == 0 {
+				classes = append(classes, c)
+				continue
+			}
+			changed = true
+			if c, ok := excludeLines(c, ignored); ok {
+				classes = append(classes, c)
+			}
+		}
+		if changed {
+			a.changed = true
+			if len(classes) == 0 {
+				continue
+			}
+			p.Classes = classes
+			p.Counters = sumClassCounters(classes)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// ignoredLines returns the excluded line numbers of a source file, reading
+// each file once.
+func (a *pragmaApplier) ignoredLines(pkg, file string) map[int]bool {
+	path, ok := findSource(a.roots, pkg, file)
+	if !ok {
+		return nil
+	}
+	if ignored, ok := a.cache[path]; ok {
+		return ignored
+	}
+	var ignored map[int]bool
+	if src, err := os.ReadFile(path); err == nil {
+		ignored = IgnoredLines(path, src)
+	}
+	a.cache[path] = ignored
+	return ignored
+}
+
+// findSource locates the source file of a class. JaCoCo records the file name
+// relative to the package directory, while Cobertura and LCOV record a path.
+func findSource(roots []string, pkg, file string) (string, bool) {
+	if file == "" {
+		return "", false
+	}
+	file = filepath.FromSlash(file)
+	if filepath.IsAbs(file) {
+		return file, isFile(file)
+	}
+	var candidates []string
+	if pkg != "" && !strings.ContainsRune(file, filepath.Separator) {
+		candidates = append(candidates, filepath.Join(filepath.FromSlash(strings.ReplaceAll(pkg, ".", "/")), file))
+	}
+	candidates = append(candidates, file)
+	for _, root := range roots {
+		for _, c := range candidates {
+			if path := filepath.Join(root, c); isFile(path) {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// excludeLines drops the ignored lines of a class and the methods that start
+// on one, and subtracts them from the class counters. A dropped method whose
+// line is not among the class lines, as in a report without line data, takes
+// its own instruction, line and branch counters along. It reports false when
+// nothing of the class is left.
+func excludeLines(c Class, ignored map[int]bool) (Class, bool) {
+	var dropped []Line
+	known := make(map[int]bool, len(c.Lines))
+	lines := make([]Line, 0, len(c.Lines))
+	for _, l := range c.Lines {
+		known[l.Number] = true
+		if ignored[l.Number] {
+			dropped = append(dropped, l)
+			continue
+		}
+		lines = append(lines, l)
+	}
+	var droppedMethods []Method
+	methods := make([]Method, 0, len(c.Methods))
+	for _, m := range c.Methods {
+		if m.Line > 0 && ignored[m.Line] {
+			droppedMethods = append(droppedMethods, m)
+			continue
+		}
+		methods = append(methods, m)
+	}
+	if len(dropped) == 0 && len(droppedMethods) == 0 {
+		return c, true
+	}
+	if len(lines) == 0 && len(methods) == 0 {
+		return Class{}, false
+	}
+
+	instruction, line, branch := lineCounters(dropped)
+	removed := []Counter{instruction, line, branch}
+	for _, m := range droppedMethods {
+		for _, mc := range m.Counters {
+			switch mc.Type {
+			case CounterMethod, CounterComplexity:
+				removed = append(removed, mc)
+			case CounterInstruction, CounterLine, CounterBranch:
+				if !known[m.Line] {
+					removed = append(removed, mc)
+				}
+			}
+		}
+	}
+	c.Lines = lines
+	c.Methods = methods
+	c.Counters = subtractCounters(c.Counters, removed)
+	return c, true
+}
+
+func subtractCounters(counters []Counter, removed []Counter) []Counter {
+	out := make([]Counter, len(counters))
+	for i, c := range counters {
+		for _, r := range removed {
+			if r.Type == c.Type {
+				c.Missed = max(c.Missed-r.Missed, 0)
+				c.Covered = max(c.Covered-r.Covered, 0)
+			}
+		}
+		c.Layers = nil
+		out[i] = c
+	}
+	return out
+}
+
+// IgnoredLines returns the 1-based numbers of the source lines excluded by
+// pragmas. name is the path of the source, whose extension tells whether #
+// starts a comment.
+func IgnoredLines(name string, src []byte) map[int]bool {
+	hash := hashCommentExts[strings.ToLower(filepath.Ext(name))]
+	lines := strings.Split(string(bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))), "\n")
+	ignored := map[int]bool{}
+	mark := func(from, to int) {
+		for i := from; i <= to && i < len(lines); i++ {
+			ignored[i+1] = true
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		text := lines[i]
+		switch {
+		case strings.Contains(text, pragmaIgnoreStart):
+			end := len(lines) - 1
+			for j := i + 1; j < len(lines); j++ {
+				if strings.Contains(lines[j], pragmaIgnoreEnd) {
+					end = j
+					break
+				}
+			}
+			mark(i, end)
+			i = end
+		case strings.Contains(text, pragmaIgnoreEnd):
+			// A stray end marker has nothing to close.
+		case strings.Contains(text, pragmaIgnore) || pragmaNoCoverRe.MatchString(text):
+			start := i
+			if strings.TrimSpace(codeOf(text, hash)) == "" {
+				mark(i, i)
+				start = nextCodeLine(lines, i+1, hash)
+				if start < 0 {
+					continue
+				}
+			}
+			if decl := declarationAfter(lines, start); decl < len(lines) {
+				mark(start, blockEnd(lines, decl, hash))
+			}
+		case isGeneratedAnnotation(text):
+			if decl := declarationAfter(lines, i); decl < len(lines) {
+				mark(i, blockEnd(lines, decl, hash))
+			}
+		}
+	}
+	return ignored
+}
+
+// declarationAfter skips the annotation (@Override) and attribute (#[test])
+// lines starting at from and returns the line of the declaration they belong
+// to, or len(lines).
+func declarationAfter(lines []string, from int) int {
+	for from < len(lines) {
+		text := strings.TrimSpace(lines[from])
+		if !strings.HasPrefix(text, "@") && !strings.HasPrefix(text, "#[") {
+			break
+		}
+		from++
+	}
+	return from
+}
+
+func isGeneratedAnnotation(text string) bool {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "@") {
+		return false
+	}
+	name, _, _ := strings.Cut(text[1:], "(")
+	name = strings.TrimSpace(name)
+	return name == annotationGen || strings.HasSuffix(name, "."+annotationGen)
+}
+
+// codeOf strips a trailing comment and empties string and character
+// literals, so that comment markers and braces inside quotes are not taken
+// for code. A quote without a closing one on the line, such as a Rust
+// lifetime, is kept as code. # starts a comment only when hash is set.
+func codeOf(text string, hash bool) string {
+	var code strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case strings.HasPrefix(text[i:], "//") || strings.HasPrefix(text[i:], "/*") || hash && ch == '#':
+			return code.String()
+		case ch == '"' || ch == '\'':
+			if end := closingQuote(text, i); end > 0 {
+				code.WriteByte(ch)
+				code.WriteByte(ch)
+				i = end
+				continue
+			}
+			code.WriteByte(ch)
+		default:
+			code.WriteByte(ch)
+		}
+	}
+	return code.String()
+}
+
+// closingQuote returns the index of the quote closing the one at start, or -1.
+func closingQuote(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case text[start]:
+			return i
+		}
+	}
+	return -1
+}
+
+func nextCodeLine(lines []string, from int, hash bool) int {
+	for i := from; i < len(lines); i++ {
+		if strings.TrimSpace(codeOf(lines[i], hash)) != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// blockEnd returns the last line of the statement starting at start: the
+// indented suite of a line ending in ":", the braces opened before the
+// statement ends, or the line itself.
+func blockEnd(lines []string, start int, hash bool) int {
+	code := strings.TrimSpace(codeOf(lines[start], hash))
+	if strings.HasSuffix(code, ":") {
+		indent := indentOf(lines[start])
+		end := start
+		for i := start + 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "" {
+				continue
+			}
+			if indentOf(lines[i]) <= indent {
+				break
+			}
+			end = i
+		}
+		return end
+	}
+
+	depth, parens, opened := 0, 0, false
+	for i := start; i < len(lines); i++ {
+		for _, r := range codeOf(lines[i], hash) {
+			switch r {
+			case '(':
+				parens++
+			case ')':
+				parens--
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+			case ';':
+				if !opened && parens <= 0 {
+					return i
+				}
+			}
+		}
+		if opened && depth <= 0 {
+			return i
+		}
+		if !opened && parens <= 0 {
+			// The statement is complete unless its body opens on the next line.
+			next := nextCodeLine(lines, i+1, hash)
+			if next < 0 || !strings.HasPrefix(strings.TrimSpace(lines[next]), "{") {
+				return i
+			}
+		}
+	}
+	return len(lines) - 1
+}
+
+func indentOf(text string) int {
+	return len(text) - len(strings.TrimLeft(text, " \t"))
+}
@@ -0,0 +1,259 @@
+package jacoco
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func sortedLines(ignored map[int]bool) []int {
+	lines := make([]int, 0, len(ignored))
+	for n := range ignored {
+		lines = append(lines, n)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+func TestIgnoredLines(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		src  string
+		want []int
+	}{
+		{
+			name: "block markers",
+			file: "A.java",
+			src: `class A {
+    // crv:ignore-start
+    void a() {}
+    // crv:ignore-end
+    void b() {}
+}`,
+			want: []int{2, 3, 4},
+		},
+		{
+			name: "generated method",
+			file: "A.java",
+			src: `class A {
+    @Generated("mapstruct")
+    @Override
+    public String toString()
+    {
+        return "";
+    }
+    void b() {}
+}`,
+			want: []int{2, 3, 4, 5, 6, 7},
+		},
+		{
+			name: "trailing and standalone comments",
+			file: "A.java",
+			src: `int a = 1; // crv:ignore
+int b = 2;
+// crv:ignore
+if (debug) {
+    log();
+}
+int c = 3;`,
+			want: []int{1, 3, 4, 5, 6},
+		},
+		{
+			name: "python block",
+			file: "a.py",
+			src: `def f():
+    return 1
+
+def debug():  # pragma: no cover
+    print("x")
+
+    return 2
+x = 1
+`,
+			want: []int{4, 5, 6, 7},
+		},
+		{
+			name: "quoted markers and braces",
+			file: "A.java",
+			src: `String url = "http://example.com/{id}"; // crv:ignore
+String a = "}";
+// crv:ignore
+void f(char c) { if (c == '{') {
+    g("#");
+} }
+int b = 1;`,
+			want: []int{1, 3, 4, 5, 6},
+		},
+		{
+			name: "upper-case pragma",
+			file: "a.py",
+			src: `if debug:  # PRAGMA: NO COVER
+    print("x")
+y = 2
+`,
+			want: []int{1, 2},
+		},
+		{
+			name: "c preprocessor lines",
+			file: "debug.c",
+			src: `int a;
+// crv:ignore
+#include "debug.h"
+int b;
+`,
+			want: []int{2, 3},
+		},
+		{
+			name: "rust attributes",
+			file: "lib.rs",
+			src: `// crv:ignore
+#[cfg(test)]
+mod tests {
+    #[test]
+    fn a() {}
+}
+fn main() {}
+`,
+			want: []int{1, 2, 3, 4, 5, 6},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := sortedLines(IgnoredLines(tc.file, []byte(tc.src)))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("ignored lines mismatch: got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplySourcePragmasRecomputesCounters(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, filepath.Join(root, "com/example/User.java"), `package com.example;
+public class User {
+    String name() {
+        return name;
+    }
+    @Generated
+    boolean same(User other) {
+        return other == this;
+    }
+}
+`)
+	writeSource(t, filepath.Join(root, "src/util.py"), "# crv:ignore-start\nx = 1\n# crv:ignore-end\n")
+
+	lines := []Line{
+		{Number: 4, CoveredInstructions: 2},
+		{Number: 8, MissedInstructions: 3, MissedBranches: 2},
+	}
+	pkgs := []Package{
+		{Name: "com/example", Classes: []Class{{
+			Name:           "com/example/User",
+			SourceFileName: "User.java",
+			Lines:          lines,
+			Methods: []Method{
+				{Name: "name", Line: 4, Counters: []Counter{{Type: CounterMethod, Covered: 1}}},
+				{Name: "same", Line: 8, Counters: []Counter{{Type: CounterMethod, Missed: 1}, {Type: CounterComplexity, Missed: 2}}},
+			},
+			Counters: []Counter{
+				{Type: CounterInstruction, Missed: 3, Covered: 2},
+				{Type: CounterBranch, Missed: 2},
+				{Type: CounterLine, Missed: 1, Covered: 1},
+				{Type: CounterComplexity, Missed: 2, Covered: 1},
+				{Type: CounterMethod, Missed: 1, Covered: 1},
+			},
+		}}},
+		{Name: "src", Classes: []Class{{
+			Name:           "util",
+			SourceFileName: "src/util.py",
+			Lines:          []Line{{Number: 2, MissedInstructions: 1}},
+			Counters:       []Counter{{Type: CounterLine, Missed: 1}},
+		}}},
+	}
+	report := Report{Packages: pkgs, Counters: sumPackageCounters(pkgs)}
+
+	got := ApplySourcePragmas(report, []string{filepath.Join(root, "missing"), root})
+	if len(got.Packages) != 1 {
+		t.Fatalf("fully ignored package should be removed: %+v", got.Packages)
+	}
+	class := got.Packages[0].Classes[0]
+	if len(class.Methods) != 1 || class.Methods[0].Name != "name" || len(class.Lines) != 1 {
+		t.Fatalf("ignored method should be removed: %+v", class)
+	}
+	want := []Counter{
+		{Type: CounterInstruction, Covered: 2},
+		{Type: CounterBranch},
+		{Type: CounterLine, Covered: 1},
+		{Type: CounterComplexity, Covered: 1},
+		{Type: CounterMethod, Covered: 1},
+	}
+	if !reflect.DeepEqual(class.Counters, want) {
+		t.Fatalf("class counters mismatch: %+v", class.Counters)
+	}
+	if c, _ := got.Counter(CounterLine); c.Missed != 0 || c.Covered != 1 {
+		t.Fatalf("report counter mismatch: %+v", c)
+	}
+}
+
+func TestApplySourcePragmasWithoutLineData(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, filepath.Join(root, "com/example/User.java"), `package com.example;
+public class User {
+    @Generated
+    public int hashCode() {
+        return 1;
+    }
+    String name() {
+        return name;
+    }
+}
+`)
+	pkgs := []Package{{Name: "com/example", Classes: []Class{{
+		Name:           "com/example/User",
+		SourceFileName: "User.java",
+		Methods: []Method{
+			{Name: "hashCode", Line: 5, Counters: []Counter{
+				{Type: CounterInstruction, Missed: 2}, {Type: CounterLine, Missed: 1}, {Type: CounterMethod, Missed: 1},
+			}},
+			{Name: "name", Line: 8, Counters: []Counter{
+				{Type: CounterInstruction, Covered: 3}, {Type: CounterLine, Covered: 1}, {Type: CounterMethod, Covered: 1},
+			}},
+		},
+		Counters: []Counter{
+			{Type: CounterInstruction, Missed: 2, Covered: 3},
+			{Type: CounterLine, Missed: 1, Covered: 1},
+			{Type: CounterMethod, Missed: 1, Covered: 1},
+		},
+	}}}}
+	report := Report{Packages: pkgs, Counters: sumPackageCounters(pkgs)}
+
+	got := ApplySourcePragmas(report, []string{root})
+	want := []Counter{
+		{Type: CounterInstruction, Covered: 3},
+		{Type: CounterLine, Covered: 1},
+		{Type: CounterMethod, Covered: 1},
+	}
+	if class := got.Packages[0].Classes[0]; !reflect.DeepEqual(class.Counters, want) {
+		t.Fatalf("dropped method counters should be subtracted: %+v", class.Counters)
+	}
+}
+
+func TestApplySourcePragmasWithoutRoots(t *testing.T) {
+	report := Report{Packages: []Package{{Name: "a", Classes: []Class{{Name: "a/A", SourceFileName: "A.java"}}}}}
+	if got := ApplySourcePragmas(report, nil); !reflect.DeepEqual(got, report) {
+		t.Fatalf("report should be unchanged: %+v", got)
+	}
+}
+
+func writeSource(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package jacoco
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Confidence is how sure a Parser is that a report prefix is in its format.
+type Confidence int
+
+const (
+	ConfidenceNone Confidence = iota
+	ConfidenceLow
+	ConfidenceHigh
+)
+
+// Parser reads one coverage report format into the Report model.
+type Parser interface {
+	// Detect inspects up to the first 64 KiB of a report.
+	Detect(prefix []byte) Confidence
+	Parse(r io.Reader) (Report, error)
+}
+
+type registeredParser struct {
+	name   string
+	parser Parser
+}
+
+var registry struct {
+	mu      sync.RWMutex
+	parsers []registeredParser
+}
+
+func init() {
+	RegisterParser(string(FormatJaCoCo), jacocoFormat{})
+	RegisterParser(string(FormatCobertura), coberturaFormat{})
+	RegisterParser(string(FormatLCOV), lcovFormat{})
+}
+
+// RegisterParser makes a format available to --format and to auto-detection
+// under name. Registering an existing name replaces its parser.
+func RegisterParser(name string, p Parser) {
+	if name == "" || name == string(FormatAuto) {
+		panic(fmt.Sprintf("jacoco: invalid parser name %q", name))
+	}
+	if p == nil {
+		panic("jacoco: RegisterParser parser is nil")
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for i, rp := range registry.parsers {
+		if rp.name == name {
+			registry.parsers[i].parser = p
+			return
+		}
+	}
+	registry.parsers = append(registry.parsers, registeredParser{name: name, parser: p})
+}
+
+// LookupParser returns the parser registered under name.
+func LookupParser(name string) (Parser, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	for _, rp := range registry.parsers {
+		if rp.name == name {
+			return rp.parser, true
+		}
+	}
+	return nil, false
+}
+
+// ParserNames lists the registered format names in registration order.
+func ParserNames() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	names := make([]string, 0, len(registry.parsers))
+	for _, rp := range registry.parsers {
+		names = append(names, rp.name)
+	}
+	return names
+}
+
+func registeredParsers() []registeredParser {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return append([]registeredParser(nil), registry.parsers...)
+}
+
+type jacocoFormat struct{}
+
+func (jacocoFormat) Detect(prefix []byte) Confidence {
+	return detectXMLRoot(prefix, "report")
+}
+
+func (f jacocoFormat) Parse(r io.Reader) (Report, error) {
+	return f.parse(r, true)
+}
+
+func (jacocoFormat) parse(r io.Reader, lines bool) (Report, error) {
+	p := jacocoParser{s: newXMLStream(r), lines: lines}
+	return p.parseReport()
+}
+
+type coberturaFormat struct{}
+
+func (coberturaFormat) Detect(prefix []byte) Confidence {
+	return detectXMLRoot(prefix, "coverage")
+}
+
+func (coberturaFormat) Parse(r io.Reader) (Report, error) {
+	return ParseCobertura(r)
+}
+
+type lcovFormat struct{}
+
+func (lcovFormat) Detect(prefix []byte) Confidence {
+	return detectLCOV(prefix)
+}
+
+func (lcovFormat) Parse(r io.Reader) (Report, error) {
+	return ParseLCOV(r)
+}
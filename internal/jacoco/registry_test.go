@@ -0,0 +1,93 @@
+package jacoco
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type toyParser struct {
+	confidence Confidence
+}
+
+func (p toyParser) Detect(prefix []byte) Confidence {
+	if bytes.HasPrefix(prefix, []byte("TOY")) {
+		return p.confidence
+	}
+	return ConfidenceNone
+}
+
+func (toyParser) Parse(r io.Reader) (Report, error) {
+	if _, err := io.ReadAll(r); err != nil {
+		return Report{}, err
+	}
+	return Report{Name: "toy", Packages: []Package{{Name: "toy"}}}, nil
+}
+
+// restoreRegistryAfter resets the parser registry when the test ends.
+func restoreRegistryAfter(t *testing.T) {
+	t.Helper()
+	orig := registeredParsers()
+	t.Cleanup(func() {
+		registry.mu.Lock()
+		registry.parsers = orig
+		registry.mu.Unlock()
+	})
+}
+
+func withRegisteredParser(t *testing.T, name string, p Parser) {
+	t.Helper()
+	restoreRegistryAfter(t)
+	RegisterParser(name, p)
+}
+
+func TestRegisteredParserTakesPartInDetectionAndParsing(t *testing.T) {
+	withRegisteredParser(t, "toy", toyParser{confidence: ConfidenceHigh})
+
+	names := ParserNames()
+	if names[len(names)-1] != "toy" {
+		t.Fatalf("registered parser missing from names: %v", names)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.toy")
+	if err := os.WriteFile(path, []byte("TOY v1\n"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	report, err := ParseWithFormatFile(path, FormatAuto, ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if report.Name != "toy" || report.Inputs[0].Format != "toy" {
+		t.Fatalf("unexpected report: name=%s format=%s", report.Name, report.Inputs[0].Format)
+	}
+}
+
+func TestDetectFormatPrefersHigherConfidence(t *testing.T) {
+	withRegisteredParser(t, "toy-low", toyParser{confidence: ConfidenceLow})
+	withRegisteredParser(t, "toy-high", toyParser{confidence: ConfidenceHigh})
+
+	got, err := DetectFormat(bytes.NewReader([]byte("TOY")))
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	if got != "toy-high" {
+		t.Fatalf("format mismatch: %s", got)
+	}
+}
+
+func TestRegisterParserReplacesExistingName(t *testing.T) {
+	withRegisteredParser(t, string(FormatLCOV), toyParser{confidence: ConfidenceHigh})
+
+	p, ok := LookupParser(string(FormatLCOV))
+	if !ok {
+		t.Fatal("lcov parser missing")
+	}
+	if _, ok := p.(toyParser); !ok {
+		t.Fatalf("lcov parser should be replaced, got %T", p)
+	}
+	if n := len(ParserNames()); n != 3 {
+		t.Fatalf("replacement should not add a name, got %d names", n)
+	}
+}
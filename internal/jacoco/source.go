@@ -0,0 +1,37 @@
+package jacoco
+
+import "sort"
+
+// SourceFile is a source file of a package with the classes compiled from
+// it, like the source file view of JaCoCo's HTML report. Classes holds
+// indexes into Package.Classes.
+type SourceFile struct {
+	Name     string
+	Classes  []int
+	Counters []Counter
+}
+
+// SourceFiles groups the classes of p by SourceFileName, in name order.
+// Classes without a source file name share an entry with an empty name.
+func SourceFiles(p Package) []SourceFile {
+	index := map[string]int{}
+	var files []SourceFile
+	for i, c := range p.Classes {
+		ix, ok := index[c.SourceFileName]
+		if !ok {
+			ix = len(files)
+			index[c.SourceFileName] = ix
+			files = append(files, SourceFile{Name: c.SourceFileName})
+		}
+		files[ix].Classes = append(files[ix].Classes, i)
+	}
+	for i := range files {
+		agg := map[CounterType]Counter{}
+		for _, ix := range files[i].Classes {
+			mergeCounters(agg, p.Classes[ix].Counters)
+		}
+		files[i].Counters = mapToCounters(agg)
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files
+}
@@ -0,0 +1,26 @@
+package jacoco
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSourceFilesGroupsClasses(t *testing.T) {
+	pkg := Package{Classes: []Class{
+		{Name: "com/example/FooKt", SourceFileName: "Foo.kt", Counters: instructions(1, 3)},
+		{Name: "com/example/Bar", SourceFileName: "Bar.kt", Counters: instructions(2, 0)},
+		{Name: "com/example/Foo", SourceFileName: "Foo.kt", Counters: instructions(0, 4)},
+		{Name: "com/example/Foo$Companion", SourceFileName: "Foo.kt", Counters: instructions(1, 1)},
+	}}
+
+	files := SourceFiles(pkg)
+	if len(files) != 2 || files[0].Name != "Bar.kt" || files[1].Name != "Foo.kt" {
+		t.Fatalf("source files mismatch: %+v", files)
+	}
+	if !reflect.DeepEqual(files[1].Classes, []int{0, 2, 3}) {
+		t.Fatalf("classes mismatch: %v", files[1].Classes)
+	}
+	if c, _ := findCounter(files[1].Counters, CounterInstruction); c.Missed != 2 || c.Covered != 8 {
+		t.Fatalf("counter mismatch: %+v", c)
+	}
+}
@@ -0,0 +1,111 @@
+package jacoco
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// xmlStream walks an XML document token by token so that parsers can build
+// the model directly without an intermediate document tree. Repeated
+// attribute values such as method descriptors and file names are interned so
+// that large reports keep a single copy of each distinct string.
+type xmlStream struct {
+	dec     *xml.Decoder
+	strings map[string]string
+}
+
+func newXMLStream(r io.Reader) *xmlStream {
+	return &xmlStream{
+		dec:     xml.NewDecoder(r),
+		strings: map[string]string{},
+	}
+}
+
+// root advances to the document element.
+func (s *xmlStream) root() (xml.StartElement, error) {
+	for {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+// next returns the next child element of the current element. ok is false
+// once the end element of the current element has been consumed.
+func (s *xmlStream) next() (start xml.StartElement, ok bool, err error) {
+	for {
+		tok, err := s.dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return xml.StartElement{}, false, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return t, true, nil
+		case xml.EndElement:
+			return xml.StartElement{}, false, nil
+		}
+	}
+}
+
+func (s *xmlStream) skip() error {
+	return s.dec.Skip()
+}
+
+func (s *xmlStream) intern(v string) string {
+	if v == "" {
+		return ""
+	}
+	if interned, ok := s.strings[v]; ok {
+		return interned
+	}
+	s.strings[v] = v
+	return v
+}
+
+func (s *xmlStream) attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (s *xmlStream) internAttr(start xml.StartElement, name string) string {
+	return s.intern(s.attr(start, name))
+}
+
+// intAttr parses a numeric attribute. Missing attributes read as zero, the
+// same as encoding/xml does for absent struct fields.
+func (s *xmlStream) intAttr(start xml.StartElement, name string) (int, error) {
+	raw := s.attr(start, name)
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("<%s %s=%q>: %w", start.Name.Local, name, raw, err)
+	}
+	return v, nil
+}
+
+func (s *xmlStream) int64Attr(start xml.StartElement, name string) (int64, error) {
+	raw := s.attr(start, name)
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("<%s %s=%q>: %w", start.Name.Local, name, raw, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,104 @@
+package jacoco
+
+import (
+	"sort"
+	"strings"
+)
+
+// PackageTree returns a copy of r in which the packages of the report and of
+// every group are nested by the segments of their names, split at / or at . for
+// names without a slash. Each intermediate segment becomes a group carrying
+// the summed counters of its descendants, and chains of segments with a single
+// child are joined, as in com/example. Groups are named by the full prefix they
+// stand for and packages keep their names, so a package that also has
+// subpackages sits next to them in the group of the same name.
+func PackageTree(r Report) Report {
+	view := r
+	view.Groups, view.Packages = treeContainer(r.Groups, r.Packages)
+	return view
+}
+
+func treeContainer(groups []Group, pkgs []Package) ([]Group, []Package) {
+	var outGroups []Group
+	if groups != nil {
+		outGroups = make([]Group, len(groups))
+		for i, g := range groups {
+			outGroups[i] = g
+			outGroups[i].Groups, outGroups[i].Packages = treeContainer(g.Groups, g.Packages)
+		}
+	}
+	root := &packageTreeNode{}
+	for _, p := range pkgs {
+		root.insert(p)
+	}
+	treeGroups, treePackages := root.build("")
+	return append(outGroups, treeGroups...), treePackages
+}
+
+type packageTreeNode struct {
+	sep      string
+	children map[string]*packageTreeNode
+	pkgs     []Package
+}
+
+func (n *packageTreeNode) insert(p Package) {
+	sep := "."
+	if strings.Contains(p.Name, "/") {
+		sep = "/"
+	}
+	segments := strings.Split(p.Name, sep)
+	if len(segments) > 1 && segments[0] == "" {
+		// Absolute paths keep their leading slash on the first segment.
+		segments = append([]string{sep + segments[1]}, segments[2:]...)
+	}
+	for _, seg := range segments {
+		if n.children == nil {
+			n.children = map[string]*packageTreeNode{}
+		}
+		child, ok := n.children[seg]
+		if !ok {
+			child = &packageTreeNode{sep: sep}
+			n.children[seg] = child
+		}
+		n = child
+	}
+	n.pkgs = append(n.pkgs, p)
+}
+
+// build turns the children of n, whose names start with prefix, into the
+// groups and packages of a container.
+func (n *packageTreeNode) build(prefix string) ([]Group, []Package) {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		groups []Group
+		pkgs   []Package
+	)
+	for _, name := range names {
+		child := n.children[name]
+		full := name
+		if prefix != "" {
+			full = prefix + child.sep + name
+		}
+		for len(child.pkgs) == 0 && len(child.children) == 1 {
+			for next, grandchild := range child.children {
+				full += child.sep + next
+				child = grandchild
+			}
+		}
+		if len(child.children) == 0 {
+			pkgs = append(pkgs, child.pkgs...)
+			continue
+		}
+		group := Group{Name: full}
+		group.Groups, group.Packages = child.build(full)
+		group.Packages = append(group.Packages, child.pkgs...)
+		group.Counters = sumContainerCounters(group.Groups, group.Packages)
+		groups = append(groups, group)
+	}
+	return groups, pkgs
+}
@@ -0,0 +1,60 @@
+package jacoco
+
+import "testing"
+
+func TestPackageTree(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "com/example/app", Counters: instructions(1, 1)},
+		{Name: "com/example/app/model", Counters: instructions(2, 0)},
+		{Name: "com/example/app/web/api", Counters: instructions(0, 4)},
+		{Name: "org/other", Counters: instructions(3, 3)},
+	}}
+
+	tree := PackageTree(report)
+	if len(tree.Groups) != 1 || len(tree.Packages) != 1 || tree.Packages[0].Name != "org/other" {
+		t.Fatalf("top level mismatch: %+v / %+v", tree.Groups, tree.Packages)
+	}
+	app := tree.Groups[0]
+	if app.Name != "com/example/app" {
+		t.Fatalf("single-child chain should be joined: %q", app.Name)
+	}
+	if c, _ := findCounter(app.Counters, CounterInstruction); c.Missed != 3 || c.Covered != 5 {
+		t.Fatalf("group counter mismatch: %+v", c)
+	}
+	if len(app.Groups) != 0 || len(app.Packages) != 3 {
+		t.Fatalf("app children mismatch: %+v / %+v", app.Groups, app.Packages)
+	}
+	for i, want := range []string{"com/example/app/model", "com/example/app/web/api", "com/example/app"} {
+		if app.Packages[i].Name != want {
+			t.Fatalf("package %d: got %q want %q", i, app.Packages[i].Name, want)
+		}
+	}
+	if len(report.Groups) != 0 || report.Packages[1].Name != "com/example/app/model" {
+		t.Fatal("source report should stay unchanged")
+	}
+}
+
+func TestPackageTreeDottedAndAbsoluteNames(t *testing.T) {
+	report := Report{
+		Groups: []Group{{Name: "module", Packages: []Package{
+			{Name: "com.example.a", Counters: instructions(1, 0)},
+			{Name: "com.example.b", Counters: instructions(0, 1)},
+		}}},
+		Packages: []Package{
+			{Name: "/home/dev/src/app", Counters: instructions(1, 0)},
+			{Name: "/home/dev/src/lib", Counters: instructions(1, 0)},
+		},
+	}
+
+	tree := PackageTree(report)
+	if len(tree.Groups) != 2 || tree.Groups[0].Name != "module" || tree.Groups[1].Name != "/home/dev/src" {
+		t.Fatalf("groups mismatch: %+v", tree.Groups)
+	}
+	module := tree.Groups[0]
+	if len(module.Groups) != 1 || module.Groups[0].Name != "com.example" || len(module.Groups[0].Packages) != 2 {
+		t.Fatalf("dotted names should nest inside existing groups: %+v", module)
+	}
+	if src := tree.Groups[1]; len(src.Packages) != 2 || src.Packages[0].Name != "/home/dev/src/app" {
+		t.Fatalf("absolute paths mismatch: %+v", src)
+	}
+}
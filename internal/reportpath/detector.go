@@ -3,21 +3,50 @@ package reportpath
 import (
 	"encoding/xml"
 	"errors"
-	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
 const (
-	mavenDefaultReportDir  = "${project.reporting.outputDirectory}/jacoco"
-	mavenDefaultSiteDir    = "target/site"
-	mavenDefaultBuildDir   = "target"
 	defaultMavenReportPath = "target/site/jacoco/jacoco.xml"
 	defaultGradlePath      = "build/reports/jacoco/test/jacocoTestReport.xml"
 )
 
-var errReportNotFound = errors.New("jacoco report xml not found")
+// Report kinds, named after the jacoco-maven-plugin goal that writes them.
+const (
+	KindReport            = "report"
+	KindReportIntegration = "report-integration"
+	KindReportAggregate   = "report-aggregate"
+)
+
+// mavenReportGoal is a jacoco-maven-plugin goal that writes jacoco.xml and
+// the directory it uses when outputDirectory is not configured.
+type mavenReportGoal struct {
+	kind       string
+	defaultDir string
+}
+
+var mavenReportGoals = []mavenReportGoal{
+	{kind: KindReport, defaultDir: "${project.reporting.outputDirectory}/jacoco"},
+	{kind: KindReportIntegration, defaultDir: "${project.reporting.outputDirectory}/jacoco-it"},
+	{kind: KindReportAggregate, defaultDir: "${project.reporting.outputDirectory}/jacoco-aggregate"},
+}
+
+// fallbackPaths are tried below a Maven module directory when its build file
+// does not lead to a report.
+var fallbackPaths = []struct {
+	rel  string
+	kind string
+}{
+	{rel: defaultMavenReportPath, kind: KindReport},
+	{rel: "target/site/jacoco-it/jacoco.xml", kind: KindReportIntegration},
+	{rel: "target/site/jacoco-aggregate/jacoco.xml", kind: KindReportAggregate},
+	{rel: defaultGradlePath, kind: KindReport},
+}
+
+var errReportNotFound = errors.New("coverage report not found")
 
 // Detect finds a JaCoCo XML report path following REQUIREMENTS.md resolution order.
 func Detect(cwd string) (string, error) {
@@ -28,45 +57,191 @@ func Detect(cwd string) (string, error) {
 	return paths[0], nil
 }
 
-// DetectAll finds JaCoCo XML report paths including maven multi-module projects.
+// Candidate is a detected report together with the build module it belongs
+// to. Module is the Maven artifactId, or the directory name when the module
+// has no pom.xml. Kind tells which report goal produced it.
+type Candidate struct {
+	Path   string
+	Module string
+	Kind   string
+}
+
+// ReportKinds lists the report kinds in detection order.
+func ReportKinds() []string {
+	kinds := make([]string, len(mavenReportGoals))
+	for i, goal := range mavenReportGoals {
+		kinds[i] = goal.kind
+	}
+	return kinds
+}
+
+// CandidateKinds returns the distinct kinds among candidates in detection
+// order.
+func CandidateKinds(candidates []Candidate) []string {
+	present := map[string]bool{}
+	for _, c := range candidates {
+		present[c.Kind] = true
+	}
+	kinds := make([]string, 0, len(present))
+	for _, kind := range ReportKinds() {
+		if present[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// FilterKind keeps the candidates of a single report kind.
+func FilterKind(candidates []Candidate, kind string) []Candidate {
+	out := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.Kind == kind {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Options tunes report detection.
+type Options struct {
+	// Properties override Maven properties, like -D on the mvn command line.
+	Properties map[string]string
+	// Trace receives the decisions of detection as a tree when set.
+	Trace io.Writer
+}
+
+// DetectAll finds coverage report paths: JaCoCo XML of Maven multi-module and
+// Gradle projects, or else the default report locations of common tools.
 func DetectAll(cwd string) ([]string, error) {
+	candidates, err := DetectCandidates(cwd, Options{})
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(candidates))
+	for i, c := range candidates {
+		paths[i] = c.Path
+	}
+	return paths, nil
+}
+
+// DetectCandidates finds JaCoCo XML reports like DetectAll and reports the
+// module each of them belongs to.
+func DetectCandidates(cwd string, opts Options) ([]Candidate, error) {
+	t := newTracer(opts.Trace, cwd)
+	candidates, err := detectCandidates(cwd, opts, t)
+	if err != nil {
+		t.printf("結果: %v", err)
+		return nil, err
+	}
+	done := t.enter("結果:")
+	for i, c := range candidates {
+		t.printf("%d. %s (module: %s, kind: %s)", i+1, t.rel(c.Path), c.Module, c.Kind)
+	}
+	done()
+	return candidates, nil
+}
+
+func detectCandidates(cwd string, opts Options, t *tracer) ([]Candidate, error) {
 	pomPath := filepath.Join(cwd, "pom.xml")
 	if fileExists(pomPath) {
+		done := t.enter("Maven: pom.xml から探索")
 		visited := map[string]struct{}{}
-		detected := detectFromPOMTree(cwd, pomPath, visited)
+		detected := detectFromPOMTree(cwd, pomPath, opts, visited, t)
+		done()
 		if len(detected) > 0 {
-			return uniquePaths(detected), nil
+			return uniqueCandidates(detected), nil
+		}
+		t.printf("Maven: レポートが見つかりませんでした")
+	} else {
+		t.printf("Maven: pom.xml がありません")
+	}
+
+	if isGradleProject(cwd) {
+		done := t.enter("Gradle: ビルドスクリプトから探索")
+		detected := detectFromGradle(cwd, t)
+		done()
+		if len(detected) > 0 {
+			return detected, nil
+		}
+		t.printf("Gradle: レポートが見つかりませんでした")
+	} else {
+		t.printf("Gradle: settings.gradle / build.gradle がありません")
+	}
+
+	done := t.enter("各ツールのデフォルト出力先を探索")
+	detected := defaultCandidates(cwd, dirModuleName(cwd), t)
+	done()
+	if len(detected) > 0 {
+		return detected, nil
+	}
+
+	return nil, &NotFoundError{Searched: searchedLocations()}
+}
+
+// ModuleOf names the Maven module that owns a report file: the artifactId of
+// the closest pom.xml in the report's directory or above it.
+func ModuleOf(reportPath string) (string, bool) {
+	abs, err := filepath.Abs(reportPath)
+	if err != nil {
+		return "", false
+	}
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		pomPath := filepath.Join(dir, "pom.xml")
+		if fileExists(pomPath) {
+			project, ok := parsePOM(pomPath)
+			if !ok {
+				return "", false
+			}
+			return projectModuleName(dir, project), true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return "", false
 		}
 	}
+}
 
-	fallback := fallbackCandidates(cwd)
-	if len(fallback) > 0 {
-		return fallback, nil
+func projectModuleName(dir string, project pomProject) string {
+	if name := strings.TrimSpace(project.ArtifactID); name != "" {
+		return name
 	}
+	return dirModuleName(dir)
+}
 
-	return nil, fmt.Errorf("%w (tried: pom.xml, %s, %s)", errReportNotFound, defaultMavenReportPath, defaultGradlePath)
+func dirModuleName(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	return filepath.Base(abs)
 }
 
-func detectFromPOMTree(cwd, pomPath string, visited map[string]struct{}) []string {
+func detectFromPOMTree(cwd, pomPath string, opts Options, visited map[string]struct{}, t *tracer) []Candidate {
 	absPom, err := filepath.Abs(pomPath)
 	if err != nil {
 		absPom = pomPath
 	}
 	if _, ok := visited[absPom]; ok {
+		t.printf("%s: 探索済みのためスキップ", t.rel(pomPath))
 		return nil
 	}
 	visited[absPom] = struct{}{}
 
-	project, ok := parsePOM(pomPath)
-	if !ok {
+	project, err := readPOM(pomPath)
+	if err != nil {
+		t.printf("✗ %s: 解析できません: %v", t.rel(pomPath), err)
 		return nil
 	}
 
-	paths := make([]string, 0)
-	if detected, ok := detectFromProject(cwd, project); ok {
-		paths = append(paths, detected)
+	module := projectModuleName(cwd, project)
+	done := t.enter("POM %s (module: %s)", t.rel(pomPath), module)
+	defer done()
+	paths := make([]Candidate, 0)
+	if detected := detectFromProject(loadMavenModel(cwd, project, pomPath, opts, t), module, t); len(detected) > 0 {
+		paths = append(paths, detected...)
 	} else {
-		paths = append(paths, fallbackCandidates(cwd)...)
+		doneFallback := t.enter("jacoco-maven-plugin の出力先にレポートが無いためデフォルトパスを確認")
+		paths = append(paths, fallbackCandidates(cwd, module, t)...)
+		doneFallback()
 	}
 
 	for _, mod := range project.Modules {
@@ -77,91 +252,112 @@ func detectFromPOMTree(cwd, pomPath string, visited map[string]struct{}) []strin
 		moduleDir := filepath.Clean(filepath.Join(cwd, module))
 		modulePom := filepath.Join(moduleDir, "pom.xml")
 		if fileExists(modulePom) {
-			paths = append(paths, detectFromPOMTree(moduleDir, modulePom, visited)...)
+			paths = append(paths, detectFromPOMTree(moduleDir, modulePom, opts, visited, t)...)
 			continue
 		}
-		paths = append(paths, fallbackCandidates(moduleDir)...)
+		doneModule := t.enter("モジュール %s: pom.xml が無いためデフォルトパスを確認", module)
+		paths = append(paths, fallbackCandidates(moduleDir, dirModuleName(moduleDir), t)...)
+		doneModule()
 	}
 
-	return uniquePaths(paths)
+	return uniqueCandidates(paths)
 }
 
-func detectFromProject(cwd string, project pomProject) (string, bool) {
-	plugins := make([]pomPlugin, 0, len(project.Build.Plugins)+len(project.Build.PluginManagement.Plugins))
-	plugins = append(plugins, project.Build.Plugins...)
-	plugins = append(plugins, project.Build.PluginManagement.Plugins...)
-
-	for _, p := range plugins {
-		if p.GroupID != "org.jacoco" || p.ArtifactID != "jacoco-maven-plugin" {
-			continue
-		}
-		reportDir := resolveReportDir(p)
-		reportDir = resolveMavenPlaceholders(reportDir)
-		candidate := filepath.Join(cwd, reportDir, "jacoco.xml")
-		if fileExists(candidate) {
-			return candidate, true
+// detectFromProject finds the report of every report goal, taking for each
+// goal the first configured plugin whose jacoco.xml exists.
+func detectFromProject(model mavenModel, module string, t *tracer) []Candidate {
+	plugins := model.jacocoPlugins()
+	if len(plugins) == 0 {
+		t.printf("jacoco-maven-plugin の設定がありません")
+		return nil
+	}
+	var found []Candidate
+	seen := map[string]struct{}{}
+	for _, goal := range mavenReportGoals {
+		done := t.enter("%s ゴール", goal.kind)
+		for _, p := range plugins {
+			dir, source := resolveReportDir(p.plugin, goal)
+			resolvedDir := model.resolvePath(dir)
+			t.printf("%s: outputDirectory %s（%s）→ %s", p.origin, dir, source, t.rel(resolvedDir))
+			if unresolved := mavenPropertyRe.FindAllString(resolvedDir, -1); len(unresolved) > 0 {
+				t.printf("未解決のプレースホルダ: %s", strings.Join(unresolved, ", "))
+			}
+			candidate := filepath.Join(resolvedDir, "jacoco.xml")
+			if _, ok := seen[candidate]; ok {
+				t.printf("%s: 他のゴールで検出済みのためスキップ", t.rel(candidate))
+				break
+			}
+			exists := fileExists(candidate)
+			t.check(candidate, exists)
+			if exists {
+				seen[candidate] = struct{}{}
+				found = append(found, Candidate{Path: candidate, Module: module, Kind: goal.kind})
+				break
+			}
 		}
+		done()
 	}
-	return "", false
+	return found
 }
 
 func parsePOM(pomPath string) (pomProject, bool) {
+	project, err := readPOM(pomPath)
+	return project, err == nil
+}
+
+func readPOM(pomPath string) (pomProject, error) {
 	content, err := os.ReadFile(pomPath)
 	if err != nil {
-		return pomProject{}, false
+		return pomProject{}, err
 	}
 	var project pomProject
 	if err := xml.Unmarshal(content, &project); err != nil {
-		return pomProject{}, false
+		return pomProject{}, err
 	}
-	return project, true
+	return project, nil
 }
 
-func fallbackCandidates(cwd string) []string {
-	found := make([]string, 0, 2)
-	for _, rel := range []string{defaultMavenReportPath, defaultGradlePath} {
-		candidate := filepath.Join(cwd, rel)
-		if fileExists(candidate) {
-			found = append(found, candidate)
+func fallbackCandidates(cwd, module string, t *tracer) []Candidate {
+	found := make([]Candidate, 0, len(fallbackPaths))
+	for _, fallback := range fallbackPaths {
+		candidate := filepath.Join(cwd, fallback.rel)
+		exists := fileExists(candidate)
+		t.check(candidate, exists)
+		if exists {
+			found = append(found, Candidate{Path: candidate, Module: module, Kind: fallback.kind})
 		}
 	}
 	return found
 }
 
-func uniquePaths(paths []string) []string {
+func uniqueCandidates(candidates []Candidate) []Candidate {
 	seen := map[string]struct{}{}
-	out := make([]string, 0, len(paths))
-	for _, path := range paths {
-		if _, ok := seen[path]; ok {
+	out := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if _, ok := seen[c.Path]; ok {
 			continue
 		}
-		seen[path] = struct{}{}
-		out = append(out, path)
+		seen[c.Path] = struct{}{}
+		out = append(out, c)
 	}
 	return out
 }
 
-func resolveReportDir(plugin pomPlugin) string {
+// resolveReportDir returns the unresolved output directory of a goal and
+// where it was configured.
+func resolveReportDir(plugin pomPlugin, goal mavenReportGoal) (string, string) {
 	for _, ex := range plugin.Executions {
-		if !hasGoal(ex.Goals, "report") {
+		if !hasGoal(ex.Goals, goal.kind) {
 			continue
 		}
 		if ex.Configuration.OutputDirectory != "" {
-			return ex.Configuration.OutputDirectory
+			return ex.Configuration.OutputDirectory, "execution の設定"
 		}
 	}
 	if plugin.Configuration.OutputDirectory != "" {
-		return plugin.Configuration.OutputDirectory
+		return plugin.Configuration.OutputDirectory, "プラグインの設定"
 	}
-	return mavenDefaultReportDir
-}
-
-func resolveMavenPlaceholders(path string) string {
-	replacer := strings.NewReplacer(
-		"${project.reporting.outputDirectory}", mavenDefaultSiteDir,
-		"${project.build.directory}", mavenDefaultBuildDir,
-	)
-	return replacer.Replace(path)
+	return goal.defaultDir, "デフォルト"
 }
 
 func hasGoal(goals []string, want string) bool {
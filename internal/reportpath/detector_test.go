@@ -91,6 +91,93 @@ func TestDetectAllFindsModuleReports(t *testing.T) {
 	}
 }
 
+func TestDetectCandidatesNamesModulesFromArtifactID(t *testing.T) {
+	dir := t.TempDir()
+
+	rootPom := `<project><artifactId>parent</artifactId><modules><module>module-a</module><module>module-b</module></modules></project>`
+	pluginPom := `<build><plugins><plugin><groupId>org.jacoco</groupId><artifactId>jacoco-maven-plugin</artifactId></plugin></plugins></build>`
+	writeFile(t, filepath.Join(dir, "pom.xml"), rootPom)
+	writeFile(t, filepath.Join(dir, "module-a/pom.xml"), `<project><parent><artifactId>parent</artifactId></parent><artifactId>billing-core</artifactId>`+pluginPom+`</project>`)
+	writeFile(t, filepath.Join(dir, "module-b/pom.xml"), `<project>`+pluginPom+`</project>`)
+	writeFile(t, filepath.Join(dir, "module-a/target/site/jacoco/jacoco.xml"), "<report name=\"a\"/>")
+	writeFile(t, filepath.Join(dir, "module-b/target/site/jacoco/jacoco.xml"), "<report name=\"b\"/>")
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect candidates failed: %v", err)
+	}
+	modules := map[string]string{}
+	for _, c := range candidates {
+		modules[c.Path] = c.Module
+	}
+	if got := modules[filepath.Join(dir, "module-a/target/site/jacoco/jacoco.xml")]; got != "billing-core" {
+		t.Fatalf("module-a should be named from artifactId, got %q", got)
+	}
+	if got := modules[filepath.Join(dir, "module-b/target/site/jacoco/jacoco.xml")]; got != "module-b" {
+		t.Fatalf("module-b should fall back to its directory name, got %q", got)
+	}
+}
+
+func TestDetectCandidatesFindsEveryReportGoal(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pom.xml"), `<project><artifactId>app</artifactId><build><plugins><plugin>
+  <groupId>org.jacoco</groupId><artifactId>jacoco-maven-plugin</artifactId>
+  <executions>
+    <execution><goals><goal>report</goal></goals></execution>
+    <execution><goals><goal>report-integration</goal></goals>
+      <configuration><outputDirectory>${project.build.directory}/it-coverage</outputDirectory></configuration></execution>
+    <execution><goals><goal>report-aggregate</goal></goals></execution>
+  </executions>
+</plugin></plugins></build></project>`)
+	writeFile(t, filepath.Join(dir, "target/site/jacoco/jacoco.xml"), "<report/>")
+	writeFile(t, filepath.Join(dir, "target/it-coverage/jacoco.xml"), "<report/>")
+	writeFile(t, filepath.Join(dir, "target/site/jacoco-aggregate/jacoco.xml"), "<report/>")
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	assertCandidates(t, candidates, []Candidate{
+		{Path: filepath.Join(dir, "target/site/jacoco/jacoco.xml"), Module: "app", Kind: KindReport},
+		{Path: filepath.Join(dir, "target/it-coverage/jacoco.xml"), Module: "app", Kind: KindReportIntegration},
+		{Path: filepath.Join(dir, "target/site/jacoco-aggregate/jacoco.xml"), Module: "app", Kind: KindReportAggregate},
+	})
+
+	kinds := CandidateKinds(candidates[1:])
+	if len(kinds) != 2 || kinds[0] != KindReportIntegration || kinds[1] != KindReportAggregate {
+		t.Fatalf("unexpected kinds: %v", kinds)
+	}
+	if got := FilterKind(candidates, KindReportIntegration); len(got) != 1 || got[0] != candidates[1] {
+		t.Fatalf("unexpected filter result: %v", got)
+	}
+}
+
+func TestDetectFallsBackToIntegrationReportDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "target/site/jacoco-it/jacoco.xml"), "<report/>")
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	assertCandidates(t, candidates, []Candidate{
+		{Path: filepath.Join(dir, "target/site/jacoco-it/jacoco.xml"), Module: filepath.Base(dir), Kind: KindReportIntegration},
+	})
+}
+
+func TestModuleOfFindsClosestPOM(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pom.xml"), `<project><artifactId>parent</artifactId></project>`)
+	writeFile(t, filepath.Join(dir, "svc/pom.xml"), `<project><artifactId>svc-api</artifactId></project>`)
+	report := filepath.Join(dir, "svc/target/site/jacoco/jacoco.xml")
+	writeFile(t, report, "<report/>")
+
+	module, ok := ModuleOf(report)
+	if !ok || module != "svc-api" {
+		t.Fatalf("unexpected module: %q (ok=%v)", module, ok)
+	}
+}
+
 func writeFile(t *testing.T, path, content string) {
 	t.Helper()
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
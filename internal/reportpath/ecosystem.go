@@ -0,0 +1,125 @@
+package reportpath
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultLocation is where a coverage tool writes its report when it is not
+// configured otherwise. A pattern with /**/ matches the file name at any depth
+// below the directory before it.
+type defaultLocation struct {
+	tool    string
+	pattern string
+	kind    string
+}
+
+// defaultLocations are searched in the working directory when no build file
+// leads to a report.
+var defaultLocations = []defaultLocation{
+	{tool: "Maven (JaCoCo)", pattern: defaultMavenReportPath, kind: KindReport},
+	{tool: "Maven (JaCoCo)", pattern: "target/site/jacoco-it/jacoco.xml", kind: KindReportIntegration},
+	{tool: "Maven (JaCoCo)", pattern: "target/site/jacoco-aggregate/jacoco.xml", kind: KindReportAggregate},
+	{tool: "Gradle (JaCoCo)", pattern: defaultGradlePath, kind: KindReport},
+	{tool: "pytest-cov", pattern: "coverage.xml", kind: KindReport},
+	{tool: "Jest / nyc", pattern: "coverage/lcov.info", kind: KindReport},
+	{tool: "cargo-llvm-cov", pattern: "lcov.info", kind: KindReport},
+	{tool: "dotnet test", pattern: "TestResults/**/coverage.cobertura.xml", kind: KindReport},
+	{tool: "Bazel", pattern: "bazel-out/_coverage/_coverage_report.dat", kind: KindReport},
+}
+
+// Location is a place that detection looked at, for error messages.
+type Location struct {
+	Tool    string
+	Pattern string
+}
+
+// NotFoundError reports that no coverage report was found and lists what was
+// searched.
+type NotFoundError struct {
+	Searched []Location
+}
+
+func (e *NotFoundError) Error() string {
+	patterns := make([]string, len(e.Searched))
+	for i, loc := range e.Searched {
+		patterns[i] = loc.Pattern
+	}
+	return errReportNotFound.Error() + " (tried: " + strings.Join(patterns, ", ") + ")"
+}
+
+func (e *NotFoundError) Unwrap() error { return errReportNotFound }
+
+func searchedLocations() []Location {
+	searched := []Location{
+		{Tool: "Maven (JaCoCo)", Pattern: "pom.xml"},
+		{Tool: "Gradle (JaCoCo)", Pattern: "settings.gradle"},
+		{Tool: "Gradle (JaCoCo)", Pattern: "build.gradle"},
+	}
+	for _, loc := range defaultLocations {
+		searched = append(searched, Location{Tool: loc.tool, Pattern: loc.pattern})
+	}
+	return searched
+}
+
+// defaultCandidates finds reports at the default locations of every tool,
+// newest first, so that the report of the last test run comes first.
+func defaultCandidates(cwd, module string, t *tracer) []Candidate {
+	type found struct {
+		candidate Candidate
+		modTime   time.Time
+	}
+	var all []found
+	for _, loc := range defaultLocations {
+		matches := matchDefaultLocation(cwd, loc.pattern)
+		if len(matches) == 0 {
+			t.printf("✗ %s (%s): 一致するファイルがありません", loc.pattern, loc.tool)
+		}
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				t.check(path, false)
+				continue
+			}
+			t.printf("✓ %s (%s, 更新日時 %s)", t.rel(path), loc.tool, info.ModTime().Format(time.DateTime))
+			all = append(all, found{
+				candidate: Candidate{Path: path, Module: module, Kind: loc.kind},
+				modTime:   info.ModTime(),
+			})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].modTime.After(all[j].modTime)
+	})
+	if len(all) > 1 {
+		t.printf("更新日時の新しい順に並べ替え")
+	}
+	candidates := make([]Candidate, len(all))
+	for i, f := range all {
+		candidates[i] = f.candidate
+	}
+	return uniqueCandidates(candidates)
+}
+
+func matchDefaultLocation(cwd, pattern string) []string {
+	dir, name, recursive := strings.Cut(pattern, "/**/")
+	if !recursive {
+		return []string{filepath.Join(cwd, filepath.FromSlash(pattern))}
+	}
+	var matches []string
+	root := filepath.Join(cwd, filepath.FromSlash(dir))
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() && d.Name() == name {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches
+}
@@ -0,0 +1,74 @@
+package reportpath
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDetectRanksDefaultLocationsByRecency(t *testing.T) {
+	dir := t.TempDir()
+	module := filepath.Base(dir)
+	writeFile(t, filepath.Join(dir, "coverage.xml"), "<coverage/>")
+	writeFile(t, filepath.Join(dir, "coverage/lcov.info"), "TN:\n")
+	writeFile(t, filepath.Join(dir, "TestResults/3f1c/coverage.cobertura.xml"), "<coverage/>")
+	writeFile(t, filepath.Join(dir, "bazel-out/_coverage/_coverage_report.dat"), "TN:\n")
+
+	base := time.Now().Add(-time.Hour)
+	touch := func(rel string, age time.Duration) {
+		t.Helper()
+		mtime := base.Add(-age)
+		if err := os.Chtimes(filepath.Join(dir, rel), mtime, mtime); err != nil {
+			t.Fatalf("chtimes failed: %v", err)
+		}
+	}
+	touch("coverage.xml", 3*time.Minute)
+	touch("coverage/lcov.info", 0)
+	touch("TestResults/3f1c/coverage.cobertura.xml", time.Minute)
+	touch("bazel-out/_coverage/_coverage_report.dat", 2*time.Minute)
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	assertCandidates(t, candidates, []Candidate{
+		{Path: filepath.Join(dir, "coverage/lcov.info"), Module: module, Kind: KindReport},
+		{Path: filepath.Join(dir, "TestResults/3f1c/coverage.cobertura.xml"), Module: module, Kind: KindReport},
+		{Path: filepath.Join(dir, "bazel-out/_coverage/_coverage_report.dat"), Module: module, Kind: KindReport},
+		{Path: filepath.Join(dir, "coverage.xml"), Module: module, Kind: KindReport},
+	})
+}
+
+func TestDetectPrefersBuildConfiguredReports(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pom.xml"), `<project><artifactId>app</artifactId></project>`)
+	writeFile(t, filepath.Join(dir, "target/site/jacoco/jacoco.xml"), "<report/>")
+	writeFile(t, filepath.Join(dir, "coverage/lcov.info"), "TN:\n")
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	assertCandidates(t, candidates, []Candidate{
+		{Path: filepath.Join(dir, "target/site/jacoco/jacoco.xml"), Module: "app", Kind: KindReport},
+	})
+}
+
+func TestDetectReportsSearchedLocations(t *testing.T) {
+	_, err := DetectCandidates(t.TempDir(), Options{})
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || !errors.Is(err, errReportNotFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	tools := map[string]bool{}
+	for _, loc := range notFound.Searched {
+		tools[loc.Tool] = true
+	}
+	for _, tool := range []string{"Maven (JaCoCo)", "Gradle (JaCoCo)", "pytest-cov", "Jest / nyc", "cargo-llvm-cov", "dotnet test", "Bazel"} {
+		if !tools[tool] {
+			t.Fatalf("%s missing from searched locations: %+v", tool, notFound.Searched)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package reportpath
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Gradle scripts are Groovy or Kotlin programs, so they are only read on a
+// best-effort basis: the common ways of declaring subprojects, build
+// directories and JaCoCo report tasks are recognized with patterns, and
+// anything else falls back to Gradle's defaults.
+
+const gradleDefaultReportTask = "jacocoTestReport"
+
+var (
+	gradleSettingsFiles = []string{"settings.gradle", "settings.gradle.kts"}
+	gradleBuildFiles    = []string{"build.gradle", "build.gradle.kts"}
+
+	gradleQuotedRe      = regexp.MustCompile(`["']([^"'\n]*)["']`)
+	gradleIncludeRe     = regexp.MustCompile(`(?m)^\s*include\b\s*(\(?)`)
+	gradleRootNameRe    = regexp.MustCompile(`rootProject\.name\s*=\s*["']([^"']+)["']`)
+	gradleProjectDirRe  = regexp.MustCompile(`project\(\s*["']([^"']+)["']\s*\)\.projectDir\s*=\s*([^\n]+)`)
+	gradleBuildDirRe    = regexp.MustCompile(`(?m)^\s*(?:project\.)?buildDir\s*=\s*([^\n]+)`)
+	gradleLayoutBuildRe = regexp.MustCompile(`layout\.buildDirectory(?:\s*=\s*|\.set\(\s*)([^\n]+)`)
+
+	gradleTaskDeclRes = []*regexp.Regexp{
+		regexp.MustCompile(`\btask\s+(\w+)\s*\(\s*type\s*:\s*JacocoReport\s*\)`),
+		regexp.MustCompile(`\btasks\.(?:register|create)\s*\(\s*["'](\w+)["']\s*,\s*JacocoReport\b[^)]*\)`),
+		regexp.MustCompile(`\btasks\.(?:register|create)\s*<\s*JacocoReport\s*>\s*\(\s*["'](\w+)["']\s*\)`),
+		regexp.MustCompile(`\bval\s+(\w+)\s+by\s+tasks\.(?:registering|creating)\s*\(\s*JacocoReport::class\s*\)`),
+	}
+	gradleWithTypeRe = regexp.MustCompile(`tasks\.withType\s*(?:<\s*JacocoReport\s*>\s*(?:\(\s*\))?|\(\s*JacocoReport(?:::class(?:\.java)?)?\s*\))\s*(?:\.configureEach\s*)?\{`)
+
+	gradleXMLLocationRe = regexp.MustCompile(`xml\.(?:outputLocation|destination)\b(?:\s*=\s*|\.set\(\s*|\s+)([^\n]+)`)
+	gradleXMLBlockRe    = regexp.MustCompile(`\bxml\s*\{`)
+	gradleLocationRe    = regexp.MustCompile(`\b(?:outputLocation|destination)\b(?:\s*=\s*|\.set\(\s*|\s+)([^\n]+)`)
+
+	gradleBuildDirFileRe   = regexp.MustCompile(`layout\.buildDirectory\.(?:file|dir)\(\s*["']([^"']*)["']`)
+	gradleProjectDirFileRe = regexp.MustCompile(`layout\.projectDirectory\.(?:file|dir)\(\s*["']([^"']*)["']`)
+	gradleFileCallRe       = regexp.MustCompile(`\b(?:file|File)\(\s*(?:[\w.]+\s*,\s*)?["']([^"']*)["']`)
+	gradleStringRe         = regexp.MustCompile(`^\s*["']([^"']*)["']`)
+)
+
+type gradleProject struct {
+	dir  string
+	name string
+}
+
+func isGradleProject(dir string) bool {
+	return firstExisting(dir, gradleSettingsFiles) != "" || firstExisting(dir, gradleBuildFiles) != ""
+}
+
+// detectFromGradle lists the JaCoCo XML reports of the root project and the
+// subprojects included from its settings script.
+func detectFromGradle(rootDir string, t *tracer) []Candidate {
+	candidates := make([]Candidate, 0)
+	for _, project := range gradleProjects(rootDir) {
+		done := t.enter("プロジェクト %s (%s)", project.name, t.rel(project.dir))
+		for _, path := range gradleReportPaths(project.dir, rootDir) {
+			exists := fileExists(path)
+			t.check(path, exists)
+			if exists {
+				candidates = append(candidates, Candidate{Path: path, Module: project.name, Kind: KindReport})
+			}
+		}
+		done()
+	}
+	return uniqueCandidates(candidates)
+}
+
+// gradleProjects reads the settings script for the root project name, the
+// included project paths such as ":service:api", and projectDir overrides.
+func gradleProjects(rootDir string) []gradleProject {
+	root := gradleProject{dir: rootDir, name: dirModuleName(rootDir)}
+	settingsPath := firstExisting(rootDir, gradleSettingsFiles)
+	if settingsPath == "" {
+		return []gradleProject{root}
+	}
+	content, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return []gradleProject{root}
+	}
+	script := stripGradleComments(string(content))
+	if m := gradleRootNameRe.FindStringSubmatch(script); m != nil {
+		root.name = m[1]
+	}
+
+	dirOverrides := map[string]string{}
+	for _, m := range gradleProjectDirRe.FindAllStringSubmatch(script, -1) {
+		if dir, ok := resolveGradlePath(m[2], rootDir, rootDir, ""); ok {
+			dirOverrides[normalizeGradleProjectPath(m[1])] = dir
+		}
+	}
+
+	projects := []gradleProject{root}
+	seen := map[string]struct{}{}
+	for _, args := range gradleIncludeArgs(script) {
+		for _, q := range gradleQuotedRe.FindAllStringSubmatch(args, -1) {
+			projectPath := normalizeGradleProjectPath(q[1])
+			if projectPath == "" {
+				continue
+			}
+			if _, ok := seen[projectPath]; ok {
+				continue
+			}
+			seen[projectPath] = struct{}{}
+			dir, ok := dirOverrides[projectPath]
+			if !ok {
+				dir = filepath.Join(rootDir, filepath.FromSlash(strings.ReplaceAll(projectPath, ":", "/")))
+			}
+			segments := strings.Split(projectPath, ":")
+			projects = append(projects, gradleProject{dir: dir, name: segments[len(segments)-1]})
+		}
+	}
+	return projects
+}
+
+// gradleIncludeArgs returns the argument lists of the include statements of a
+// settings script: up to the matching parenthesis for include(...), which may
+// span lines, and otherwise the line plus the lines continued by a trailing
+// comma, as in Groovy's include 'a',\n 'b'.
+func gradleIncludeArgs(script string) []string {
+	var out []string
+	for _, m := range gradleIncludeRe.FindAllStringSubmatchIndex(script, -1) {
+		if m[3] > m[2] {
+			out = append(out, gradleBlockAt(script, m[2]))
+			continue
+		}
+		rest := script[m[1]:]
+		end := 0
+		for {
+			line, _, _ := strings.Cut(rest[end:], "\n")
+			end += len(line)
+			if !strings.HasSuffix(strings.TrimSpace(line), ",") || end >= len(rest) {
+				break
+			}
+			end++
+		}
+		out = append(out, rest[:end])
+	}
+	return out
+}
+
+func normalizeGradleProjectPath(p string) string {
+	return strings.Trim(strings.TrimSpace(p), ":")
+}
+
+// gradleReportPaths returns where the project's JaCoCo report tasks write
+// their XML report: jacocoTestReport plus any custom JacocoReport task.
+func gradleReportPaths(projectDir, rootDir string) []string {
+	buildDir := filepath.Join(projectDir, "build")
+	buildPath := firstExisting(projectDir, gradleBuildFiles)
+	if buildPath == "" {
+		return []string{filepath.Join(buildDir, "reports", "jacoco", "test", gradleDefaultReportTask+".xml")}
+	}
+	content, err := os.ReadFile(buildPath)
+	if err != nil {
+		return nil
+	}
+	script := stripGradleComments(string(content))
+
+	if m := gradleLayoutBuildRe.FindStringSubmatch(script); m != nil {
+		if dir, ok := resolveGradlePath(m[1], projectDir, rootDir, buildDir); ok {
+			buildDir = dir
+		}
+	} else if m := gradleBuildDirRe.FindStringSubmatch(script); m != nil {
+		if dir, ok := resolveGradlePath(m[1], projectDir, rootDir, buildDir); ok {
+			buildDir = dir
+		}
+	}
+
+	shared := ""
+	for _, loc := range gradleWithTypeRe.FindAllStringIndex(script, -1) {
+		if path, ok := xmlLocationIn(gradleBlockAt(script, loc[1]-1), projectDir, rootDir, buildDir); ok {
+			shared = path
+		}
+	}
+
+	tasks := []string{gradleDefaultReportTask}
+	declBlocks := map[string][]string{}
+	for _, re := range gradleTaskDeclRes {
+		for _, m := range re.FindAllStringSubmatchIndex(script, -1) {
+			name := script[m[2]:m[3]]
+			if _, ok := declBlocks[name]; !ok && name != gradleDefaultReportTask {
+				tasks = append(tasks, name)
+			}
+			if block := gradleBlockAfter(script, m[1]); block != "" {
+				declBlocks[name] = append(declBlocks[name], block)
+			} else if _, ok := declBlocks[name]; !ok {
+				declBlocks[name] = nil
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		path := filepath.Join(buildDir, "reports", "jacoco", task, task+".xml")
+		if task == gradleDefaultReportTask {
+			path = filepath.Join(buildDir, "reports", "jacoco", "test", task+".xml")
+		}
+		if shared != "" {
+			path = shared
+		}
+		blocks := append(append([]string(nil), declBlocks[task]...), gradleTaskBlocks(script, task)...)
+		for _, block := range blocks {
+			if p, ok := xmlLocationIn(block, projectDir, rootDir, buildDir); ok {
+				path = p
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// gradleTaskBlocks finds configuration blocks of a task written as
+// "name { ... }", "tasks.name { ... }" or "tasks.named("name") { ... }".
+func gradleTaskBlocks(script, task string) []string {
+	quoted := regexp.QuoteMeta(task)
+	res := []*regexp.Regexp{
+		regexp.MustCompile(`(?:^|[^\w.])(?:tasks\.)?` + quoted + `\s*\{`),
+		regexp.MustCompile(`tasks\.(?:named|getByName)\s*(?:<\s*JacocoReport\s*>)?\(\s*["']` + quoted + `["'][^)]*\)\s*(?:\.configure\s*)?\{`),
+	}
+	var blocks []string
+	for _, re := range res {
+		for _, loc := range re.FindAllStringIndex(script, -1) {
+			blocks = append(blocks, gradleBlockAt(script, loc[1]-1))
+		}
+	}
+	return blocks
+}
+
+// gradleBlockAfter returns the block that starts right after pos, if any.
+func gradleBlockAfter(script string, pos int) string {
+	rest := strings.TrimLeft(script[pos:], " \t\r\n")
+	if !strings.HasPrefix(rest, "{") {
+		return ""
+	}
+	return gradleBlockAt(script, len(script)-len(rest))
+}
+
+// gradleBlockAt returns the text between the brace or parenthesis at open and
+// its match.
+func gradleBlockAt(script string, open int) string {
+	opening, closing := script[open], byte('}')
+	if opening == '(' {
+		closing = ')'
+	}
+	depth := 0
+	var quote byte
+	for i := open; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == opening:
+			depth++
+		case c == closing:
+			depth--
+			if depth == 0 {
+				return script[open+1 : i]
+			}
+		}
+	}
+	return script[open+1:]
+}
+
+func xmlLocationIn(block, projectDir, rootDir, buildDir string) (string, bool) {
+	if m := gradleXMLLocationRe.FindStringSubmatch(block); m != nil {
+		return resolveGradlePath(m[1], projectDir, rootDir, buildDir)
+	}
+	for _, loc := range gradleXMLBlockRe.FindAllStringIndex(block, -1) {
+		if m := gradleLocationRe.FindStringSubmatch(gradleBlockAt(block, loc[1]-1)); m != nil {
+			return resolveGradlePath(m[1], projectDir, rootDir, buildDir)
+		}
+	}
+	return "", false
+}
+
+// resolveGradlePath evaluates the usual ways of writing a file location:
+// layout.buildDirectory.file("x"), file("x"), new File(dir, "x") or a plain
+// string, with $buildDir, $projectDir and $rootDir interpolated.
+func resolveGradlePath(expr, projectDir, rootDir, buildDir string) (string, bool) {
+	var raw string
+	base := projectDir
+	switch {
+	case gradleBuildDirFileRe.MatchString(expr):
+		raw = gradleBuildDirFileRe.FindStringSubmatch(expr)[1]
+		base = buildDir
+	case gradleProjectDirFileRe.MatchString(expr):
+		raw = gradleProjectDirFileRe.FindStringSubmatch(expr)[1]
+	case gradleFileCallRe.MatchString(expr):
+		raw = gradleFileCallRe.FindStringSubmatch(expr)[1]
+	case gradleStringRe.MatchString(expr):
+		raw = gradleStringRe.FindStringSubmatch(expr)[1]
+	default:
+		return "", false
+	}
+	if base == "" {
+		return "", false
+	}
+
+	replacements := []string{
+		"${rootDir}", rootDir, "$rootDir", rootDir,
+		"${rootProject.projectDir}", rootDir,
+		"${projectDir}", projectDir, "$projectDir", projectDir,
+		"${project.projectDir}", projectDir,
+	}
+	if buildDir != "" {
+		replacements = append(replacements,
+			"${layout.buildDirectory.get().asFile}", buildDir,
+			"${layout.buildDirectory.get()}", buildDir,
+			"${project.buildDir}", buildDir, "$project.buildDir", buildDir,
+			"${buildDir}", buildDir, "$buildDir", buildDir,
+		)
+	}
+	resolved := strings.NewReplacer(replacements...).Replace(raw)
+	if strings.Contains(resolved, "$") {
+		return "", false
+	}
+	resolved = filepath.FromSlash(resolved)
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(base, resolved)
+	}
+	return filepath.Clean(resolved), true
+}
+
+// stripGradleComments removes // and /* */ comments outside string literals.
+func stripGradleComments(script string) string {
+	var b strings.Builder
+	b.Grow(len(script))
+	var quote byte
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(script) {
+				i++
+				b.WriteByte(script[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			b.WriteByte(c)
+		case strings.HasPrefix(script[i:], "//"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				return b.String()
+			}
+			i += end - 1
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func firstExisting(dir string, names []string) string {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
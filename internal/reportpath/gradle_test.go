@@ -0,0 +1,160 @@
+package reportpath
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectGradleSubprojectsFromSettings(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "settings.gradle.kts"), `
+rootProject.name = "shop"
+// include(":legacy")
+include(":service:api", ":service:core")
+include("web")
+`)
+	writeFile(t, filepath.Join(dir, "service/api/build.gradle.kts"), `plugins { jacoco }`)
+	writeFile(t, filepath.Join(dir, "service/api/build/reports/jacoco/test/jacocoTestReport.xml"), "<report/>")
+	writeFile(t, filepath.Join(dir, "web/build/reports/jacoco/test/jacocoTestReport.xml"), "<report/>")
+	writeFile(t, filepath.Join(dir, "legacy/build/reports/jacoco/test/jacocoTestReport.xml"), "<report/>")
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	want := []Candidate{
+		{Path: filepath.Join(dir, "service/api/build/reports/jacoco/test/jacocoTestReport.xml"), Module: "api", Kind: KindReport},
+		{Path: filepath.Join(dir, "web/build/reports/jacoco/test/jacocoTestReport.xml"), Module: "web", Kind: KindReport},
+	}
+	assertCandidates(t, candidates, want)
+}
+
+func TestGradleProjectsFromMultiLineIncludes(t *testing.T) {
+	for _, tc := range []struct {
+		name, file, script string
+	}{
+		{name: "kotlin", file: "settings.gradle.kts", script: `
+include(
+    ":app",
+    ":lib",
+)
+`},
+		{name: "groovy", file: "settings.gradle", script: `
+include 'app',
+        'lib'
+rootProject.name = 'shop'
+`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, tc.file), tc.script)
+			var names []string
+			for _, p := range gradleProjects(dir)[1:] {
+				names = append(names, p.name)
+			}
+			if len(names) != 2 || names[0] != "app" || names[1] != "lib" {
+				t.Fatalf("subprojects mismatch: %v", names)
+			}
+		})
+	}
+}
+
+func TestDetectGradleXMLOutputLocationAndBuildDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "settings.gradle"), `include 'app', 'lib'`)
+	writeFile(t, filepath.Join(dir, "app/build.gradle"), `
+buildDir = 'out'
+jacocoTestReport {
+    reports {
+        html.outputLocation = file("$buildDir/html")
+        xml.outputLocation = file("$buildDir/coverage/app.xml")
+    }
+}
+`)
+	writeFile(t, filepath.Join(dir, "app/out/coverage/app.xml"), "<report/>")
+	writeFile(t, filepath.Join(dir, "lib/build.gradle.kts"), `
+layout.buildDirectory.set(file("target"))
+tasks.jacocoTestReport {
+    reports {
+        xml {
+            required.set(true)
+            outputLocation.set(layout.buildDirectory.file("jacoco/lib.xml"))
+        }
+    }
+}
+`)
+	writeFile(t, filepath.Join(dir, "lib/target/jacoco/lib.xml"), "<report/>")
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	want := []Candidate{
+		{Path: filepath.Join(dir, "app/out/coverage/app.xml"), Module: "app", Kind: KindReport},
+		{Path: filepath.Join(dir, "lib/target/jacoco/lib.xml"), Module: "lib", Kind: KindReport},
+	}
+	assertCandidates(t, candidates, want)
+}
+
+func TestDetectGradleCustomJacocoReportTasks(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "build.gradle"), `
+task jacocoIntegrationReport(type: JacocoReport) {
+    executionData integrationTest
+}
+tasks.register("jacocoE2eReport", JacocoReport) {
+    reports { xml.destination file("reports/e2e.xml") }
+}
+`)
+	writeFile(t, filepath.Join(dir, "build/reports/jacoco/test/jacocoTestReport.xml"), "<report/>")
+	writeFile(t, filepath.Join(dir, "build/reports/jacoco/jacocoIntegrationReport/jacocoIntegrationReport.xml"), "<report/>")
+	writeFile(t, filepath.Join(dir, "reports/e2e.xml"), "<report/>")
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	module := filepath.Base(dir)
+	want := []Candidate{
+		{Path: filepath.Join(dir, "build/reports/jacoco/test/jacocoTestReport.xml"), Module: module, Kind: KindReport},
+		{Path: filepath.Join(dir, "build/reports/jacoco/jacocoIntegrationReport/jacocoIntegrationReport.xml"), Module: module, Kind: KindReport},
+		{Path: filepath.Join(dir, "reports/e2e.xml"), Module: module, Kind: KindReport},
+	}
+	assertCandidates(t, candidates, want)
+}
+
+func TestDetectGradleProjectDirOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "settings.gradle"), `
+include ':core'
+project(':core').projectDir = file('modules/core-impl')
+`)
+	writeFile(t, filepath.Join(dir, "modules/core-impl/build/reports/jacoco/test/jacocoTestReport.xml"), "<report/>")
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	assertCandidates(t, candidates, []Candidate{
+		{Path: filepath.Join(dir, "modules/core-impl/build/reports/jacoco/test/jacocoTestReport.xml"), Module: "core", Kind: KindReport},
+	})
+}
+
+func TestStripGradleCommentsKeepsStrings(t *testing.T) {
+	got := stripGradleComments("url = \"http://example.com\" // note\n/* block\n */x = 1")
+	if got != "url = \"http://example.com\" \nx = 1" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+func assertCandidates(t *testing.T, got, want []Candidate) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("candidate count mismatch: got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("candidate %d mismatch: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
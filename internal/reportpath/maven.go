@@ -0,0 +1,205 @@
+package reportpath
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxParentDepth bounds the parent POM chain, guarding against cycles that
+// the visited set does not catch, such as symlinked directories.
+const maxParentDepth = 16
+
+var mavenPropertyRe = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// mavenModel is the part of a Maven effective POM that report detection needs:
+// the module's own POM, its parents found through <relativePath>, and the
+// properties visible to it.
+type mavenModel struct {
+	dir     string
+	project pomProject
+	// parents lists the parent POMs, nearest first.
+	parents []pomProject
+	props   map[string]string
+	user    map[string]string
+}
+
+// declaredPlugin is a jacoco-maven-plugin declaration and where it was found.
+type declaredPlugin struct {
+	plugin pomPlugin
+	origin string
+}
+
+func loadMavenModel(dir string, project pomProject, pomPath string, opts Options, t *tracer) mavenModel {
+	m := mavenModel{
+		dir:     dir,
+		project: project,
+		props:   map[string]string{},
+		user:    opts.Properties,
+	}
+
+	visited := map[string]struct{}{}
+	if abs, err := filepath.Abs(pomPath); err == nil {
+		visited[abs] = struct{}{}
+	}
+	child, childPath := project, pomPath
+	for len(m.parents) < maxParentDepth {
+		if child.Parent.ArtifactID == "" {
+			break
+		}
+		parent, parentPath, err := loadParentPOM(child, childPath)
+		if err != nil {
+			t.printf("親 POM %s をたどりません: %v", child.Parent.ArtifactID, err)
+			break
+		}
+		abs, err := filepath.Abs(parentPath)
+		if err != nil {
+			abs = parentPath
+		}
+		if _, seen := visited[abs]; seen {
+			t.printf("親 POM %s: 循環しているため停止", t.rel(parentPath))
+			break
+		}
+		visited[abs] = struct{}{}
+		t.printf("親 POM: %s (artifactId: %s)", t.rel(parentPath), parent.ArtifactID)
+		m.parents = append(m.parents, parent)
+		child, childPath = parent, parentPath
+	}
+
+	// Nearer POMs override the properties of their ancestors.
+	for i := len(m.parents) - 1; i >= 0; i-- {
+		for k, v := range m.parents[i].Properties {
+			m.props[k] = v
+		}
+	}
+	for k, v := range project.Properties {
+		m.props[k] = v
+	}
+	return m
+}
+
+// loadParentPOM follows <parent><relativePath>, which defaults to
+// ../pom.xml. A POM found there that is not the declared parent is ignored,
+// as Maven does.
+func loadParentPOM(child pomProject, childPath string) (pomProject, string, error) {
+	rel := "../pom.xml"
+	if child.Parent.RelativePath != nil {
+		rel = strings.TrimSpace(*child.Parent.RelativePath)
+	}
+	if rel == "" {
+		return pomProject{}, "", errors.New("relativePath が空です")
+	}
+	parentPath := filepath.Join(filepath.Dir(childPath), filepath.FromSlash(rel))
+	if info, err := os.Stat(parentPath); err == nil && info.IsDir() {
+		parentPath = filepath.Join(parentPath, "pom.xml")
+	}
+	parent, err := readPOM(parentPath)
+	if err != nil {
+		return pomProject{}, "", err
+	}
+	if got := strings.TrimSpace(parent.ArtifactID); got != strings.TrimSpace(child.Parent.ArtifactID) {
+		return pomProject{}, "", fmt.Errorf("%s の artifactId が %s です", parentPath, got)
+	}
+	return parent, parentPath, nil
+}
+
+// jacocoPlugins returns the jacoco-maven-plugin declarations that apply to
+// the module, its own first and then those inherited from parents.
+func (m mavenModel) jacocoPlugins() []declaredPlugin {
+	var plugins []declaredPlugin
+	for _, project := range append([]pomProject{m.project}, m.parents...) {
+		sections := []struct {
+			name    string
+			plugins []pomPlugin
+		}{
+			{name: "plugins", plugins: project.Build.Plugins},
+			{name: "pluginManagement", plugins: project.Build.PluginManagement.Plugins},
+		}
+		for _, section := range sections {
+			for _, p := range section.plugins {
+				if p.GroupID == "org.jacoco" && p.ArtifactID == "jacoco-maven-plugin" {
+					origin := strings.TrimSpace(project.ArtifactID) + " の " + section.name
+					plugins = append(plugins, declaredPlugin{plugin: p, origin: origin})
+				}
+			}
+		}
+	}
+	return plugins
+}
+
+// resolvePath interpolates properties in a POM path value and makes it
+// absolute relative to the module directory.
+func (m mavenModel) resolvePath(value string) string {
+	resolved := filepath.FromSlash(m.resolve(strings.TrimSpace(value)))
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(m.dir, resolved)
+	}
+	return filepath.Clean(resolved)
+}
+
+// resolve replaces ${...} references. Unknown references are kept as they
+// are, so the resulting path simply does not exist.
+func (m mavenModel) resolve(value string) string {
+	return m.resolveDepth(value, 0)
+}
+
+func (m mavenModel) resolveDepth(value string, depth int) string {
+	if depth > maxParentDepth {
+		return value
+	}
+	return mavenPropertyRe.ReplaceAllStringFunc(value, func(ref string) string {
+		v, ok := m.lookup(ref[2 : len(ref)-1])
+		if !ok {
+			return ref
+		}
+		return m.resolveDepth(v, depth+1)
+	})
+}
+
+func (m mavenModel) lookup(name string) (string, bool) {
+	if v, ok := m.user[name]; ok {
+		return v, true
+	}
+	switch name {
+	case "project.basedir", "basedir", "pom.basedir":
+		return m.dir, true
+	case "project.build.directory":
+		return m.inherited(func(p pomProject) string { return p.Build.Directory }, "${project.basedir}/target"), true
+	case "project.build.outputDirectory":
+		return m.inherited(func(p pomProject) string { return p.Build.OutputDirectory }, "${project.build.directory}/classes"), true
+	case "project.reporting.outputDirectory":
+		return m.inherited(func(p pomProject) string { return p.Reporting.OutputDirectory }, "${project.build.directory}/site"), true
+	case "project.artifactId":
+		return m.project.ArtifactID, true
+	case "project.groupId":
+		return m.inherited(func(p pomProject) string { return p.GroupID }, m.project.Parent.GroupID), true
+	case "project.version":
+		return m.inherited(func(p pomProject) string { return p.Version }, m.project.Parent.Version), true
+	case "project.parent.artifactId":
+		return m.project.Parent.ArtifactID, true
+	case "project.parent.groupId":
+		return m.project.Parent.GroupID, true
+	case "project.parent.version":
+		return m.project.Parent.Version, true
+	}
+	if env, ok := strings.CutPrefix(name, "env."); ok {
+		return os.LookupEnv(env)
+	}
+	v, ok := m.props[name]
+	return v, ok
+}
+
+// inherited returns the first non-empty value of field from the module or
+// its parents. Values are resolved in the module's context, so an inherited
+// ${project.basedir} points at the module, as in Maven.
+func (m mavenModel) inherited(field func(pomProject) string, fallback string) string {
+	for _, project := range append([]pomProject{m.project}, m.parents...) {
+		if v := strings.TrimSpace(field(project)); v != "" {
+			return v
+		}
+	}
+	return fallback
+}
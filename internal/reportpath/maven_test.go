@@ -0,0 +1,106 @@
+package reportpath
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectResolvesMavenPropertiesAndBuildDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pom.xml"), `<project>
+  <artifactId>app</artifactId>
+  <properties><coverage.dir>${project.build.directory}/coverage/${project.artifactId}</coverage.dir></properties>
+  <build>
+    <directory>${project.basedir}/out</directory>
+    <plugins><plugin><groupId>org.jacoco</groupId><artifactId>jacoco-maven-plugin</artifactId>
+      <configuration><outputDirectory>${coverage.dir}</outputDirectory></configuration>
+    </plugin></plugins>
+  </build>
+</project>`)
+	writeFile(t, filepath.Join(dir, "out/coverage/app/jacoco.xml"), "<report/>")
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	assertCandidates(t, candidates, []Candidate{
+		{Path: filepath.Join(dir, "out/coverage/app/jacoco.xml"), Module: "app", Kind: KindReport},
+	})
+}
+
+func TestDetectInheritsJacocoConfigFromParentPOM(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "parent/pom.xml"), `<project>
+  <artifactId>parent</artifactId>
+  <properties><jacoco.out>${project.reporting.outputDirectory}/coverage</jacoco.out></properties>
+  <build><pluginManagement><plugins><plugin>
+    <groupId>org.jacoco</groupId><artifactId>jacoco-maven-plugin</artifactId>
+    <configuration><outputDirectory>${jacoco.out}</outputDirectory></configuration>
+  </plugin></plugins></pluginManagement></build>
+</project>`)
+	writeFile(t, filepath.Join(dir, "pom.xml"), `<project>
+  <parent><artifactId>parent</artifactId><relativePath>parent/pom.xml</relativePath></parent>
+  <artifactId>service</artifactId>
+  <reporting><outputDirectory>${project.basedir}/reports</outputDirectory></reporting>
+</project>`)
+	writeFile(t, filepath.Join(dir, "reports/coverage/jacoco.xml"), "<report/>")
+
+	candidates, err := DetectCandidates(dir, Options{})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	assertCandidates(t, candidates, []Candidate{
+		{Path: filepath.Join(dir, "reports/coverage/jacoco.xml"), Module: "service", Kind: KindReport},
+	})
+}
+
+func TestDetectIgnoresParentWithDifferentArtifactID(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pom.xml"), `<project><artifactId>other</artifactId>
+  <build><pluginManagement><plugins><plugin>
+    <groupId>org.jacoco</groupId><artifactId>jacoco-maven-plugin</artifactId>
+    <configuration><outputDirectory>wrong</outputDirectory></configuration>
+  </plugin></plugins></pluginManagement></build>
+</project>`)
+	child := filepath.Join(dir, "child")
+	writeFile(t, filepath.Join(child, "pom.xml"), `<project>
+  <parent><artifactId>parent</artifactId></parent>
+  <artifactId>child</artifactId>
+</project>`)
+	writeFile(t, filepath.Join(child, "wrong/jacoco.xml"), "<report/>")
+
+	m := loadMavenModel(child, mustParsePOM(t, filepath.Join(child, "pom.xml")), filepath.Join(child, "pom.xml"), Options{}, nil)
+	if len(m.parents) != 0 || len(m.jacocoPlugins()) != 0 {
+		t.Fatalf("unrelated parent should be ignored: %+v", m.parents)
+	}
+}
+
+func TestDetectAppliesCommandLineProperties(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pom.xml"), `<project>
+  <artifactId>app</artifactId>
+  <properties><coverage.dir>target/default</coverage.dir></properties>
+  <build><plugins><plugin><groupId>org.jacoco</groupId><artifactId>jacoco-maven-plugin</artifactId>
+    <configuration><outputDirectory>${coverage.dir}</outputDirectory></configuration>
+  </plugin></plugins></build>
+</project>`)
+	writeFile(t, filepath.Join(dir, "target/default/jacoco.xml"), "<report/>")
+	writeFile(t, filepath.Join(dir, "target/ci/jacoco.xml"), "<report/>")
+
+	candidates, err := DetectCandidates(dir, Options{Properties: map[string]string{"coverage.dir": "target/ci"}})
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	assertCandidates(t, candidates, []Candidate{
+		{Path: filepath.Join(dir, "target/ci/jacoco.xml"), Module: "app", Kind: KindReport},
+	})
+}
+
+func mustParsePOM(t *testing.T, path string) pomProject {
+	t.Helper()
+	project, ok := parsePOM(path)
+	if !ok {
+		t.Fatalf("failed to parse %s", path)
+	}
+	return project
+}
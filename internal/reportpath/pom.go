@@ -1,17 +1,43 @@
 package reportpath
 
+import (
+	"encoding/xml"
+	"strings"
+)
+
 type pomProject struct {
-	Modules []string `xml:"modules>module"`
-	Build   pomBuild `xml:"build"`
+	GroupID    string        `xml:"groupId"`
+	ArtifactID string        `xml:"artifactId"`
+	Version    string        `xml:"version"`
+	Parent     pomParent     `xml:"parent"`
+	Properties pomProperties `xml:"properties"`
+	Modules    []string      `xml:"modules>module"`
+	Build      pomBuild      `xml:"build"`
+	Reporting  pomReporting  `xml:"reporting"`
+}
+
+type pomParent struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	// RelativePath is nil when the element is absent, which means the Maven
+	// default ../pom.xml; an empty element disables the lookup.
+	RelativePath *string `xml:"relativePath"`
 }
 
 type pomBuild struct {
+	Directory        string      `xml:"directory"`
+	OutputDirectory  string      `xml:"outputDirectory"`
 	Plugins          []pomPlugin `xml:"plugins>plugin"`
 	PluginManagement struct {
 		Plugins []pomPlugin `xml:"plugins>plugin"`
 	} `xml:"pluginManagement"`
 }
 
+type pomReporting struct {
+	OutputDirectory string `xml:"outputDirectory"`
+}
+
 type pomPlugin struct {
 	GroupID       string           `xml:"groupId"`
 	ArtifactID    string           `xml:"artifactId"`
@@ -28,3 +54,27 @@ type pomConfiguration struct {
 	OutputDirectory string `xml:"outputDirectory"`
 	DataFile        string `xml:"dataFile"`
 }
+
+// pomProperties holds the free-form children of <properties>.
+type pomProperties map[string]string
+
+func (p *pomProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	props := pomProperties{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			props[t.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			*p = props
+			return nil
+		}
+	}
+}
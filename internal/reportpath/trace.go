@@ -0,0 +1,62 @@
+package reportpath
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// tracer writes the decisions of report detection as an indented tree. A
+// nil tracer discards everything, so detection code can trace
+// unconditionally.
+type tracer struct {
+	w     io.Writer
+	root  string
+	depth int
+}
+
+func newTracer(w io.Writer, root string) *tracer {
+	if w == nil {
+		return nil
+	}
+	return &tracer{w: w, root: root}
+}
+
+func (t *tracer) printf(format string, args ...any) {
+	if t == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(t.w, "%s%s\n", strings.Repeat("  ", t.depth), fmt.Sprintf(format, args...))
+}
+
+// enter prints a line and indents what follows until the returned function
+// is called.
+func (t *tracer) enter(format string, args ...any) func() {
+	if t == nil {
+		return func() {}
+	}
+	t.printf(format, args...)
+	t.depth++
+	return func() { t.depth-- }
+}
+
+// check traces whether a candidate report file exists.
+func (t *tracer) check(path string, found bool) {
+	if found {
+		t.printf("✓ %s", t.rel(path))
+		return
+	}
+	t.printf("✗ %s: ファイルがありません", t.rel(path))
+}
+
+// rel shortens paths below the detection root.
+func (t *tracer) rel(path string) string {
+	if t == nil {
+		return path
+	}
+	if rel, err := filepath.Rel(t.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,57 @@
+package reportpath
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectTracesDecisions(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pom.xml"), `<project><artifactId>root</artifactId><modules><module>core</module><module>broken</module></modules></project>`)
+	writeFile(t, filepath.Join(dir, "core/pom.xml"), `<project><artifactId>core</artifactId><build><plugins><plugin>
+  <groupId>org.jacoco</groupId><artifactId>jacoco-maven-plugin</artifactId>
+  <configuration><outputDirectory>${coverage.dir}</outputDirectory></configuration>
+</plugin></plugins></build></project>`)
+	writeFile(t, filepath.Join(dir, "broken/pom.xml"), `<project>`)
+	writeFile(t, filepath.Join(dir, "core/target/site/jacoco/jacoco.xml"), "<report/>")
+
+	var trace bytes.Buffer
+	if _, err := DetectCandidates(dir, Options{Trace: &trace}); err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	got := trace.String()
+	for _, want := range []string{
+		"Maven: pom.xml から探索\n",
+		"  POM pom.xml (module: root)\n",
+		"    POM core/pom.xml (module: core)\n",
+		"core の plugins: outputDirectory ${coverage.dir}（プラグインの設定）",
+		"未解決のプレースホルダ: ${coverage.dir}\n",
+		"✗ core/${coverage.dir}/jacoco.xml: ファイルがありません\n",
+		"✓ core/target/site/jacoco/jacoco.xml\n",
+		"✗ broken/pom.xml: 解析できません: ",
+		"結果:\n  1. core/target/site/jacoco/jacoco.xml (module: core, kind: report)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("trace should contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestDetectTracesSearchedDefaults(t *testing.T) {
+	var trace bytes.Buffer
+	if _, err := DetectCandidates(t.TempDir(), Options{Trace: &trace}); err == nil {
+		t.Fatal("expected not found error")
+	}
+	got := trace.String()
+	for _, want := range []string{
+		"Maven: pom.xml がありません\n",
+		"  ✗ TestResults/**/coverage.cobertura.xml (dotnet test): 一致するファイルがありません\n",
+		"結果: coverage report not found",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("trace should contain %q:\n%s", want, got)
+		}
+	}
+}
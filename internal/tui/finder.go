@@ -0,0 +1,354 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
+)
+
+// finderEntry is a package, class or method the finder can jump to. stack is
+// the navigation below the report that shows it; a method is a row of the
+// last class node.
+type finderEntry struct {
+	tag      string
+	label    string
+	counters []jacoco.Counter
+	stack    []navNode
+	method   int
+}
+
+type finderMatch struct {
+	entry int
+	score int
+}
+
+// indexFinder indexes every package, class and method of the displayed
+// report. Labels are full names, prefixed with the groups that are not part
+// of the package name, such as module > com/example/Foo.
+func (m Model) indexFinder() []finderEntry {
+	var entries []finderEntry
+	var walk func(path []int, groups []string, stack []navNode)
+	walk = func(path []int, groups []string, stack []navNode) {
+		childGroups, pkgs := m.containerAt(path)
+		for i, g := range childGroups {
+			groupPath := appendPath(path, i)
+			walk(groupPath, append(groups[:len(groups):len(groups)], g.Name), withNode(stack, navNode{kind: nodeGroup, groupPath: groupPath}))
+		}
+		for i, pkg := range pkgs {
+			prefix := finderPrefix(groups, pkg.Name)
+			node := navNode{kind: nodePackage, groupPath: path, packageIx: i}
+			pkgStack := withNode(stack, node)
+			entries = append(entries, finderEntry{tag: "package", label: prefix + pkg.Name, counters: pkg.Counters, stack: pkgStack, method: -1})
+			for j, class := range pkg.Classes {
+				classNode := navNode{kind: nodeClass, groupPath: path, packageIx: i, classIx: j}
+				entries = m.appendClassEntries(entries, prefix, pkg, class, withNode(pkgStack, classNode))
+			}
+		}
+	}
+	walk(nil, nil, nil)
+	return entries
+}
+
+func (m Model) appendClassEntries(entries []finderEntry, prefix string, pkg jacoco.Package, class jacoco.Class, stack []navNode) []finderEntry {
+	label := prefix + finderClassName(pkg.Name, class.Name)
+	entries = append(entries, finderEntry{tag: "class", label: label, counters: class.Counters, stack: stack, method: -1})
+	node := stack[len(stack)-1]
+	for i, nested := range class.Nested {
+		child := node
+		child.nested = appendPath(node.nested, i)
+		entries = m.appendClassEntries(entries, prefix, pkg, nested, withNode(stack, child))
+	}
+	for i, method := range class.Methods {
+		if m.hiddenMethod(class, method) {
+			continue
+		}
+		entries = append(entries, finderEntry{
+			tag:      "method",
+			label:    label + "." + methodDisplayName(class, method, m.config.QualifiedTypes, m.raw),
+			counters: method.Counters,
+			stack:    stack,
+			method:   i,
+		})
+	}
+	return entries
+}
+
+func withNode(stack []navNode, n navNode) []navNode {
+	out := make([]navNode, 0, len(stack)+1)
+	out = append(out, stack...)
+	return append(out, n)
+}
+
+// finderPrefix keeps the group names that the package name does not already
+// start with, as for the modules of an aggregate report.
+func finderPrefix(groups []string, pkg string) string {
+	var prefix strings.Builder
+	for _, g := range groups {
+		if !strings.HasPrefix(pkg, g) {
+			prefix.WriteString(g + " > ")
+		}
+	}
+	return prefix.String()
+}
+
+// finderClassName qualifies class names that formats such as LCOV record
+// without their package.
+func finderClassName(pkg, class string) string {
+	if strings.HasPrefix(class, pkg) {
+		return class
+	}
+	sep := "."
+	if strings.Contains(pkg, "/") || strings.Contains(class, "/") {
+		sep = "/"
+	}
+	return pkg + sep + class
+}
+
+// rankFinder returns the entries matching query, best first. An empty query
+// keeps the index order.
+func rankFinder(entries []finderEntry, query string) []finderMatch {
+	matches := make([]finderMatch, 0, len(entries))
+	for i, e := range entries {
+		if score, ok := fuzzyScore(query, e.label); ok {
+			matches = append(matches, finderMatch{entry: i, score: score})
+		}
+	}
+	if query == "" {
+		return matches
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		return len(entries[a.entry].label) < len(entries[b.entry].label)
+	})
+	return matches
+}
+
+// Scores follow fzf: every matched rune earns a base score, runes that start
+// a word or follow the previous match earn bonuses and gaps cost a penalty.
+const (
+	scoreMatch          = 16
+	scoreGapStart       = -3
+	scoreGapExtension   = -1
+	bonusBoundary       = 8
+	bonusCamel          = 7
+	bonusConsecutive    = 4
+	bonusFirstCharRatio = 2
+)
+
+// fuzzyScore matches the runes of pattern in order anywhere in text. The
+// match is case-insensitive unless pattern has an upper-case rune. Like fzf
+// v1 it takes the first occurrence of the last rune and then the shortest
+// span ending there.
+func fuzzyScore(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	p := []rune(pattern)
+	t := []rune(text)
+	caseSensitive := strings.IndexFunc(pattern, unicode.IsUpper) >= 0
+	equal := func(a, b rune) bool {
+		if caseSensitive {
+			return a == b
+		}
+		return unicode.ToLower(a) == unicode.ToLower(b)
+	}
+
+	pi, end := 0, -1
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if equal(p[pi], t[ti]) {
+			pi++
+			if pi == len(p) {
+				end = ti
+			}
+		}
+	}
+	if end < 0 {
+		return 0, false
+	}
+	pi, start := len(p)-1, end
+	for ti := end; ti >= 0; ti-- {
+		if equal(p[pi], t[ti]) {
+			start = ti
+			pi--
+			if pi < 0 {
+				break
+			}
+		}
+	}
+
+	score, pi := 0, 0
+	inGap, consecutive := false, 0
+	for ti := start; ti <= end; ti++ {
+		if pi < len(p) && equal(p[pi], t[ti]) {
+			bonus := charBonus(t, ti)
+			if consecutive > 0 {
+				bonus = max(bonus, bonusConsecutive)
+			}
+			if pi == 0 {
+				bonus *= bonusFirstCharRatio
+			}
+			score += scoreMatch + bonus
+			pi++
+			consecutive++
+			inGap = false
+			continue
+		}
+		if inGap {
+			score += scoreGapExtension
+		} else {
+			score += scoreGapStart
+		}
+		inGap, consecutive = true, 0
+	}
+	return score, true
+}
+
+// charBonus rewards a rune that starts a word: the first rune, one after a
+// separator and an upper-case rune after a lower-case one.
+func charBonus(t []rune, i int) int {
+	if i == 0 {
+		return bonusBoundary
+	}
+	prev, cur := t[i-1], t[i]
+	switch {
+	case strings.ContainsRune("/.$_-: >", prev):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamel
+	case !unicode.IsDigit(prev) && unicode.IsDigit(cur):
+		return bonusCamel
+	}
+	return 0
+}
+
+func (m *Model) startFinder() {
+	m.finderMode = true
+	m.finderQuery = ""
+	m.finderEntries = m.indexFinder()
+	m.updateFinder()
+}
+
+func (m *Model) closeFinder() {
+	m.finderMode = false
+	m.finderQuery = ""
+	m.finderEntries = nil
+	m.finderMatches = nil
+}
+
+func (m *Model) updateFinder() {
+	m.finderMatches = rankFinder(m.finderEntries, m.finderQuery)
+	m.finderCursor = 0
+}
+
+func (m *Model) applyFinderKey(key string) (quit bool) {
+	switch key {
+	case "esc":
+		m.closeFinder()
+	case "enter":
+		if m.finderCursor < len(m.finderMatches) {
+			m.jumpTo(m.finderEntries[m.finderMatches[m.finderCursor].entry])
+		}
+		m.closeFinder()
+	case "up", "ctrl+p", "ctrl+k":
+		if m.finderCursor > 0 {
+			m.finderCursor--
+		}
+	case "down", "ctrl+n", "ctrl+j":
+		if m.finderCursor < len(m.finderMatches)-1 {
+			m.finderCursor++
+		}
+	case "backspace":
+		if m.finderQuery != "" {
+			runes := []rune(m.finderQuery)
+			m.finderQuery = string(runes[:len(runes)-1])
+			m.updateFinder()
+		}
+	case "ctrl+c":
+		return true
+	default:
+		if isPrintableKey(key) {
+			m.finderQuery += key
+			m.updateFinder()
+		}
+	}
+	return false
+}
+
+// jumpTo rebuilds the navigation stack down to an entry, with the cursor of
+// every level on the node below it, so that going back walks up the path the
+// entry was found at.
+func (m *Model) jumpTo(e finderEntry) {
+	m.filterMode = false
+	m.filterQuery = ""
+	m.bySource = false
+	stack := withNode(nil, navNode{kind: nodeReport})
+	stack = append(stack, e.stack...)
+	for i := range stack {
+		m.stack = stack[:i+1]
+		var kind nodeKind
+		var index int
+		switch {
+		case i+1 < len(stack):
+			kind, index = childKey(stack[i+1])
+		case e.method >= 0:
+			kind, index = nodeReport, e.method
+		default:
+			continue
+		}
+		rows := m.currentChildren()
+		for j, row := range rows {
+			if row.kind == kind && row.index == index {
+				stack[i].cursor = j
+				break
+			}
+		}
+		m.ensureCursorVisible(len(rows))
+	}
+	m.stack = stack
+}
+
+// childKey identifies the row that opens n in the children of its parent.
+// Method rows have no kind of their own and use nodeReport.
+func childKey(n navNode) (nodeKind, int) {
+	switch {
+	case n.kind == nodeGroup:
+		return nodeGroup, n.groupPath[len(n.groupPath)-1]
+	case n.kind == nodePackage:
+		return nodePackage, n.packageIx
+	case len(n.nested) > 0:
+		return nodeClass, n.nested[len(n.nested)-1]
+	default:
+		return nodeClass, n.classIx
+	}
+}
+
+func (m Model) renderFinder() string {
+	lines := []string{m.headerStyle.Render(fmt.Sprintf("Find (%d/%d, %s)", len(m.finderMatches), len(m.finderEntries), m.counterLabel()))}
+	if len(m.finderMatches) == 0 {
+		return lines[0] + "\n(no matches)"
+	}
+	maxRows := min(m.maxVisibleChildren(), len(m.finderMatches))
+	offset := max(0, m.finderCursor-maxRows+1)
+	nameWidth := max(12, m.width-28)
+	for i, match := range m.finderMatches[offset : offset+maxRows] {
+		e := m.finderEntries[match.entry]
+		marker := " "
+		style := m.itemStyle
+		if offset+i == m.finderCursor {
+			marker = "❯"
+			style = style.Inherit(m.cursorStyle)
+		}
+		coverage := coverageForType(e.counters, m.counterType)
+		name := compactNameForDisplay(e.label, nameWidth)
+		line := fmt.Sprintf("%s %-7s %6.1f%% %s", marker, e.tag, coverage, name)
+		style = style.Inherit(m.styleForCoverage(coverage, m.counterType))
+		lines = append(lines, style.Render(line))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
+)
+
+func TestFuzzyScoreRanksWordStarts(t *testing.T) {
+	entries := []finderEntry{
+		{label: "com/example/FastObjectOutput"},
+		{label: "com/example/web/UserController.findOne(long): User"},
+		{label: "com/example/Foo"},
+		{label: "com/example/service/UserService"},
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"foo", "com/example/Foo"},
+		{"usrsvc", "com/example/service/UserService"},
+		{"UC", "com/example/web/UserController.findOne(long): User"},
+	}
+	for _, tc := range tests {
+		matches := rankFinder(entries, tc.query)
+		if len(matches) == 0 || entries[matches[0].entry].label != tc.want {
+			t.Fatalf("%q: best match mismatch: %+v", tc.query, matches)
+		}
+	}
+	if _, ok := fuzzyScore("xyz", "com/example/Foo"); ok {
+		t.Fatal("unmatched runes should not match")
+	}
+	if _, ok := fuzzyScore("FOO", "com/example/foo"); ok {
+		t.Fatal("an upper-case query should match case-sensitively")
+	}
+}
+
+func TestFinderJumpRebuildsStack(t *testing.T) {
+	report := jacoco.Report{Groups: []jacoco.Group{{
+		Name: "module",
+		Packages: []jacoco.Package{
+			{Name: "com/example/a", Classes: []jacoco.Class{{Name: "com/example/a/Alpha"}}},
+			{Name: "com/example/b", Classes: []jacoco.Class{
+				{Name: "com/example/b/Beta"},
+				{Name: "com/example/b/Gamma", Methods: []jacoco.Method{
+					{Name: "run", Desc: "()V", Counters: []jacoco.Counter{{Type: jacoco.CounterInstruction, Covered: 1}}},
+					{Name: "stop", Desc: "()V"},
+				}},
+			}},
+		},
+	}}}
+	m := NewModel(report, Config{Sort: "name"})
+	m.applyKey("ctrl+p")
+	if !m.finderMode || len(m.finderEntries) != 7 {
+		t.Fatalf("finder should index every node: %d", len(m.finderEntries))
+	}
+	for _, key := range []string{"g", "a", "m", "s", "t", "o"} {
+		m.applyKey(key)
+	}
+	if e := m.finderEntries[m.finderMatches[0].entry]; e.label != "module > com/example/b/Gamma.stop()" {
+		t.Fatalf("best match mismatch: %q", e.label)
+	}
+	m.applyKey("enter")
+
+	if m.finderMode || len(m.stack) != 4 || m.current().kind != nodeClass || m.current().classIx != 1 {
+		t.Fatalf("stack mismatch: %+v", m.stack)
+	}
+	if rows := m.currentChildren(); rows[m.current().cursor].name != "stop()" {
+		t.Fatalf("cursor should be on the method: %+v", rows)
+	}
+	m.applyKey("b")
+	if rows := m.currentChildren(); rows[m.current().cursor].name != "com/example/b/Gamma" {
+		t.Fatalf("back should land on the class: %+v", rows)
+	}
+	m.applyKey("b")
+	if rows := m.currentChildren(); rows[m.current().cursor].name != "com/example/b" {
+		t.Fatalf("back should land on the package: %+v", rows)
+	}
+}
+
+func TestFinderFollowsPackageTree(t *testing.T) {
+	report := jacoco.Report{Packages: []jacoco.Package{
+		{Name: "com/example/a", Classes: []jacoco.Class{{Name: "com/example/a/Alpha"}}},
+		{Name: "com/example/b", Classes: []jacoco.Class{{Name: "com/example/b/Beta"}}},
+	}}
+	m := NewModel(report, Config{Sort: "name", Tree: true})
+	m.applyKey("ctrl+p")
+	for _, key := range []string{"b", "e", "t", "a"} {
+		m.applyKey(key)
+	}
+	m.applyKey("enter")
+	if got := m.renderBreadcrumb(); got != "Report > com/example > b > com/example/b/Beta" {
+		t.Fatalf("breadcrumb mismatch: %q", got)
+	}
+	m.applyKey("ctrl+p")
+	if view := m.View(); !strings.Contains(view, "find> ") || !strings.Contains(view, "package") {
+		t.Fatalf("finder overlay should be shown: %q", view)
+	}
+	m.applyKey("esc")
+	if m.finderMode || len(m.stack) != 4 {
+		t.Fatal("esc should close the finder and keep the view")
+	}
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Key binding actions. Bindings map keys as reported by bubbletea, such as
+// "k", "up" or "ctrl+c", to these actions.
+const (
+	actionQuit    = "quit"
+	actionUp      = "up"
+	actionDown    = "down"
+	actionTop     = "top"
+	actionBottom  = "bottom"
+	actionOpen    = "open"
+	actionBack    = "back"
+	actionSort    = "sort"
+	actionCounter = "counter"
+	actionFilter  = "filter"
+	actionFind    = "find"
+	actionInfo    = "info"
+	actionLayer   = "layer"
+	actionFold    = "fold"
+	actionSource  = "source"
+	actionRaw     = "raw"
+	actionTree    = "tree"
+)
+
+var defaultKeys = map[string][]string{
+	actionQuit:    {"q", "ctrl+c"},
+	actionUp:      {"up", "k"},
+	actionDown:    {"down", "j"},
+	actionTop:     {"g"},
+	actionBottom:  {"G"},
+	actionOpen:    {"enter"},
+	actionBack:    {"b", "backspace"},
+	actionSort:    {"s"},
+	actionCounter: {"c"},
+	actionFilter:  {"/"},
+	actionFind:    {"ctrl+p"},
+	actionInfo:    {"i"},
+	actionLayer:   {"l"},
+	actionFold:    {"z"},
+	actionSource:  {"f"},
+	actionRaw:     {"m"},
+	actionTree:    {"t"},
+}
+
+// KeyActions lists the actions that can be bound.
+func KeyActions() []string {
+	actions := make([]string, 0, len(defaultKeys))
+	for action := range defaultKeys {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// KeyMap holds the keys bound to each action.
+type KeyMap struct {
+	bindings map[string][]string
+	actions  map[string]string
+}
+
+// NewKeyMap starts from the default bindings and replaces the keys of every
+// action in overrides.
+func NewKeyMap(overrides map[string][]string) (KeyMap, error) {
+	bindings := make(map[string][]string, len(defaultKeys))
+	for action, keys := range defaultKeys {
+		bindings[action] = keys
+	}
+	for action, keys := range overrides {
+		if _, ok := defaultKeys[action]; !ok {
+			return KeyMap{}, fmt.Errorf("不明なアクションです: %s（%s）", action, strings.Join(KeyActions(), " / "))
+		}
+		if len(keys) == 0 {
+			return KeyMap{}, fmt.Errorf("%s のキーが空です", action)
+		}
+		bindings[action] = keys
+	}
+
+	actions := map[string]string{}
+	for _, action := range KeyActions() {
+		for _, key := range bindings[action] {
+			if other, ok := actions[key]; ok {
+				return KeyMap{}, fmt.Errorf("キー %s が %s と %s に重複して割り当てられています", key, other, action)
+			}
+			actions[key] = action
+		}
+	}
+	return KeyMap{bindings: bindings, actions: actions}, nil
+}
+
+func defaultKeyMap() KeyMap {
+	km, _ := NewKeyMap(nil)
+	return km
+}
+
+// Keys returns the keys bound to an action.
+func (km KeyMap) Keys(action string) []string {
+	return km.bindings[action]
+}
+
+func (km KeyMap) action(key string) string {
+	return km.actions[key]
+}
+
+// label shows the first key of an action the way the help line writes keys.
+func (km KeyMap) label(action string) string {
+	keys := km.bindings[action]
+	if len(keys) == 0 {
+		return "-"
+	}
+	return keyLabel(keys[0])
+}
+
+// pairLabel shows the keys of two opposite actions side by side, such as
+// "↑/↓ or k/j".
+func (km KeyMap) pairLabel(first, second string) string {
+	a, b := km.bindings[first], km.bindings[second]
+	n := min(len(a), len(b))
+	pairs := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		pairs = append(pairs, keyLabel(a[i])+"/"+keyLabel(b[i]))
+	}
+	return strings.Join(pairs, " or ")
+}
+
+func keyLabel(key string) string {
+	switch key {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "enter":
+		return "Enter"
+	default:
+		return key
+	}
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCustomKeyBindings(t *testing.T) {
+	keys, err := NewKeyMap(map[string][]string{
+		"down": {"n"},
+		"up":   {"p"},
+		"quit": {"x"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := NewModel(sampleReport(), Config{Threshold: 80, Keys: keys})
+	m.report.Packages = append(m.report.Packages, m.report.Packages[0])
+
+	m.applyKey("j")
+	if m.current().cursor != 0 {
+		t.Fatal("replaced key should no longer move the cursor")
+	}
+	m.applyKey("n")
+	if m.current().cursor != 1 {
+		t.Fatalf("custom down key should move the cursor, got %d", m.current().cursor)
+	}
+	m.applyKey("p")
+	if m.current().cursor != 0 {
+		t.Fatalf("custom up key should move the cursor, got %d", m.current().cursor)
+	}
+	if m.applyKey("q") {
+		t.Fatal("q should not quit after rebinding")
+	}
+	if !m.applyKey("x") {
+		t.Fatal("x should quit")
+	}
+	if help := m.renderHelp(); !strings.Contains(help, "p/n: move") || !strings.Contains(help, "x: quit") {
+		t.Fatalf("help should show custom keys: %q", help)
+	}
+}
+
+func TestNewKeyMapRejectsInvalidBindings(t *testing.T) {
+	for _, overrides := range []map[string][]string{
+		{"jump": {"x"}},
+		{"quit": {}},
+		{"quit": {"s"}},
+	} {
+		if _, err := NewKeyMap(overrides); err == nil {
+			t.Fatalf("expected error for %v", overrides)
+		}
+	}
+}
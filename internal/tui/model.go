@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"path"
 	"sort"
 	"strings"
 	"time"
@@ -11,21 +12,38 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/izuno4t/coverage-report-viewer-cli/internal/demangle"
 	"github.com/izuno4t/coverage-report-viewer-cli/internal/jacoco"
 )
 
 type Config struct {
 	Threshold int
-	Sort      string
-	NoColor   bool
-	Watch     bool
+	// Thresholds overrides Threshold for individual counter types.
+	Thresholds map[jacoco.CounterType]int
+	Sort       string
+	NoColor    bool
+	Watch      bool
+	// Fold starts the viewer with nested classes folded into their top-level
+	// class. ShowSynthetic lists accessor and bridge methods.
+	Fold          bool
+	ShowSynthetic bool
+	// Tree starts the viewer with packages nested by name segments.
+	Tree bool
+	// QualifiedTypes shows the package of the types in method signatures.
+	QualifiedTypes bool
+	// Theme and Keys fall back to DefaultTheme and the default bindings when
+	// left zero.
+	Theme Theme
+	Keys  KeyMap
 }
 
 type nodeKind int
 
 const (
 	nodeReport nodeKind = iota
+	nodeGroup
 	nodePackage
+	nodeSource
 	nodeClass
 )
 
@@ -40,27 +58,53 @@ const (
 )
 
 type navNode struct {
-	kind      nodeKind
+	kind nodeKind
+	// groupPath is the chain of group indexes from the report root. For a
+	// group node it points at the group itself; for package and class nodes
+	// it points at the container holding the package.
+	groupPath []int
 	packageIx int
-	classIx   int
-	cursor    int
-	offset    int
+	// sourceIx indexes jacoco.SourceFiles of the package for a source node.
+	sourceIx int
+	classIx  int
+	// nested is the chain of Class.Nested indexes below the class at classIx.
+	nested []int
+	cursor int
+	offset int
 }
 
 type Model struct {
-	report      jacoco.Report
+	// source is the report as loaded; report is the view of it that is
+	// displayed, such as a single layer of a merged report.
+	source    jacoco.Report
+	report    jacoco.Report
+	layer     int
+	layerOnly bool
+	fold      bool
+	tree      bool
+	bySource  bool
+	// raw shows method names as recorded instead of demangling them.
+	raw         bool
 	config      Config
+	keys        KeyMap
 	stack       []navNode
 	sortID      string
 	counterType jacoco.CounterType
 	filterMode  bool
 	filterQuery string
-	reloadFn    func() (jacoco.Report, error)
-	probeFn     func() (bool, error)
-	watchPrompt bool
-	watchErr    string
-	width       int
-	height      int
+	// finderEntries indexes the whole report while the finder is open.
+	finderMode    bool
+	finderQuery   string
+	finderCursor  int
+	finderEntries []finderEntry
+	finderMatches []finderMatch
+	showInfo      bool
+	reloadFn      func() (jacoco.Report, error)
+	probeFn       func() (bool, error)
+	watchPrompt   bool
+	watchErr      string
+	width         int
+	height        int
 
 	titleStyle  lipgloss.Style
 	headerStyle lipgloss.Style
@@ -74,9 +118,21 @@ func NewModel(report jacoco.Report, cfg Config) Model {
 }
 
 func newModel(report jacoco.Report, cfg Config, reloadFn func() (jacoco.Report, error), probeFn func() (bool, error)) Model {
+	if cfg.Theme == (Theme{}) {
+		cfg.Theme = DefaultTheme()
+	}
+	keys := cfg.Keys
+	if keys.bindings == nil {
+		keys = defaultKeyMap()
+	}
 	m := Model{
+		source:      report,
 		report:      report,
+		layer:       -1,
+		fold:        cfg.Fold,
+		tree:        cfg.Tree,
 		config:      cfg,
+		keys:        keys,
 		stack:       []navNode{{kind: nodeReport, cursor: 0, offset: 0}},
 		sortID:      normalizeInitialSort(cfg.Sort),
 		counterType: jacoco.CounterInstruction,
@@ -94,11 +150,12 @@ func newModel(report jacoco.Report, cfg Config, reloadFn func() (jacoco.Report,
 		helpStyle: lipgloss.NewStyle().
 			Faint(true),
 	}
+	m.refreshView()
 	if !cfg.NoColor {
-		m.titleStyle = m.titleStyle.Foreground(lipgloss.Color(draculaPurple))
-		m.headerStyle = m.headerStyle.Foreground(lipgloss.Color(draculaCyan))
-		m.cursorStyle = m.cursorStyle.Foreground(lipgloss.Color(draculaPink))
-		m.helpStyle = m.helpStyle.Foreground(lipgloss.Color(draculaComment))
+		m.titleStyle = m.titleStyle.Foreground(lipgloss.Color(cfg.Theme.Title))
+		m.headerStyle = m.headerStyle.Foreground(lipgloss.Color(cfg.Theme.Header))
+		m.cursorStyle = m.cursorStyle.Foreground(lipgloss.Color(cfg.Theme.Cursor))
+		m.helpStyle = m.helpStyle.Foreground(lipgloss.Color(cfg.Theme.Help))
 	}
 	return m
 }
@@ -146,9 +203,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.watchErr = msg.err.Error()
 			return m, nil
 		}
-		m.report = msg.report
+		m.source = msg.report
+		if m.layer >= len(m.source.Layers) {
+			m.layer, m.layerOnly = -1, false
+		}
+		m.refreshView()
 		m.watchErr = ""
 		m.watchPrompt = false
+		m.closeFinder()
 		m.stack = []navNode{{kind: nodeReport, cursor: 0, offset: 0}}
 		return m, nil
 	case tea.KeyMsg:
@@ -177,27 +239,45 @@ func (m *Model) applyKey(key string) (quit bool) {
 	if m.filterMode {
 		return m.applyFilterKey(key)
 	}
-	switch key {
-	case "q", "ctrl+c":
+	if m.finderMode {
+		return m.applyFinderKey(key)
+	}
+	switch m.keys.action(key) {
+	case actionQuit:
 		return true
-	case "up", "k":
+	case actionUp:
 		m.moveCursor(-1)
-	case "down", "j":
+	case actionDown:
 		m.moveCursor(1)
-	case "g":
+	case actionTop:
 		m.jumpToStart()
-	case "G":
+	case actionBottom:
 		m.jumpToEnd()
-	case "enter":
+	case actionOpen:
 		m.enterChild()
-	case "b", "backspace":
+	case actionBack:
 		m.goBack()
-	case "s":
+	case actionSort:
 		m.toggleSort()
-	case "c":
+	case actionCounter:
 		m.toggleCounterType()
-	case "/":
+	case actionFilter:
 		m.startFilter()
+	case actionFind:
+		m.startFinder()
+	case actionInfo:
+		m.showInfo = !m.showInfo
+		m.ensureCursorVisible(m.visibleChildCount())
+	case actionLayer:
+		m.cycleLayer()
+	case actionFold:
+		m.toggleFold()
+	case actionTree:
+		m.toggleTree()
+	case actionSource:
+		m.toggleSourceView()
+	case actionRaw:
+		m.raw = !m.raw
 	}
 	return false
 }
@@ -289,6 +369,85 @@ func (m *Model) toggleCounterType() {
 	m.current().offset = 0
 }
 
+// cycleLayer steps through the union of all inputs, each input on its own,
+// and what each input alone covers.
+func (m *Model) cycleLayer() {
+	layers := len(m.source.Layers)
+	if layers < 2 {
+		return
+	}
+	switch {
+	case m.layer < 0:
+		m.layer, m.layerOnly = 0, false
+	case m.layer < layers-1:
+		m.layer++
+	case !m.layerOnly:
+		m.layer, m.layerOnly = 0, true
+	default:
+		m.layer, m.layerOnly = -1, false
+	}
+	m.refreshView()
+}
+
+// toggleFold switches between listing nested classes on their own and
+// folding them into their top-level class. Class indexes differ between the
+// two, so the view returns to the package.
+func (m *Model) toggleFold() {
+	m.fold = !m.fold
+	m.refreshView()
+	for len(m.stack) > 1 && m.current().kind == nodeClass {
+		m.stack = m.stack[:len(m.stack)-1]
+	}
+	m.current().cursor = 0
+	m.current().offset = 0
+}
+
+// toggleTree switches the package list between flat names and a tree nested
+// by name segments. Group and package indexes differ between the two, so the
+// view returns to the report.
+func (m *Model) toggleTree() {
+	m.tree = !m.tree
+	m.refreshView()
+	m.stack = m.stack[:1]
+	m.current().cursor = 0
+	m.current().offset = 0
+}
+
+// toggleSourceView switches the package screen between classes and the
+// source files they were compiled from.
+func (m *Model) toggleSourceView() {
+	if m.current().kind != nodePackage {
+		return
+	}
+	m.bySource = !m.bySource
+	m.current().cursor = 0
+	m.current().offset = 0
+}
+
+func (m *Model) refreshView() {
+	m.report = m.source
+	if m.layer >= 0 {
+		m.report = jacoco.LayerView(m.source, m.layer, m.layerOnly)
+	}
+	if m.fold {
+		m.report = jacoco.FoldNestedClasses(m.report)
+	}
+	if m.tree {
+		m.report = jacoco.PackageTree(m.report)
+	}
+}
+
+func (m Model) layerLabel() string {
+	switch {
+	case m.layer < 0:
+		return "union"
+	case m.layerOnly:
+		return "only " + m.source.Layers[m.layer]
+	default:
+		return m.source.Layers[m.layer]
+	}
+}
+
 func (m *Model) moveCursor(delta int) {
 	current := m.current()
 	childCount := m.visibleChildCount()
@@ -337,27 +496,69 @@ func (m *Model) enterChild() {
 	selected := children[current.cursor]
 
 	switch current.kind {
-	case nodeReport:
+	case nodeReport, nodeGroup:
+		if selected.kind == nodeGroup {
+			m.stack = append(m.stack, navNode{
+				kind:      nodeGroup,
+				groupPath: appendPath(current.groupPath, selected.index),
+				cursor:    0,
+				offset:    0,
+			})
+			return
+		}
 		m.stack = append(m.stack, navNode{
 			kind:      nodePackage,
+			groupPath: current.groupPath,
 			packageIx: selected.index,
 			cursor:    0,
 			offset:    0,
 		})
-	case nodePackage:
+	case nodePackage, nodeSource:
+		if selected.kind == nodeSource {
+			m.stack = append(m.stack, navNode{
+				kind:      nodeSource,
+				groupPath: current.groupPath,
+				packageIx: current.packageIx,
+				sourceIx:  selected.index,
+				cursor:    0,
+				offset:    0,
+			})
+			return
+		}
+		if selected.kind != nodeClass {
+			return
+		}
 		m.stack = append(m.stack, navNode{
 			kind:      nodeClass,
+			groupPath: current.groupPath,
 			packageIx: current.packageIx,
 			classIx:   selected.index,
 			cursor:    0,
 			offset:    0,
 		})
 	case nodeClass:
-		// Method level is the leaf in current milestone.
-		return
+		// Methods are leaves; only folded nested classes open.
+		if selected.kind != nodeClass {
+			return
+		}
+		m.stack = append(m.stack, navNode{
+			kind:      nodeClass,
+			groupPath: current.groupPath,
+			packageIx: current.packageIx,
+			classIx:   current.classIx,
+			nested:    appendPath(current.nested, selected.index),
+			cursor:    0,
+			offset:    0,
+		})
 	}
 }
 
+func appendPath(path []int, ix int) []int {
+	out := make([]int, 0, len(path)+1)
+	out = append(out, path...)
+	return append(out, ix)
+}
+
 func (m *Model) goBack() {
 	if len(m.stack) <= 1 {
 		return
@@ -376,10 +577,19 @@ func (m Model) View() string {
 		"",
 		summary,
 		"",
-		m.renderChildren(),
-		"",
-		m.helpStyle.Render(fmt.Sprintf("sort: %s  counter: %s  filter: %s | ↑/↓ or j/k: move  g/G: jump  Enter: open  b: back  s: sort  c: counter  /: filter  q: quit", m.sortLabel(), m.counterLabel(), m.filterLabel())),
 	}
+	if m.showInfo {
+		parts = append(parts, m.renderInfo(), "")
+	}
+	children := m.renderChildren()
+	if m.finderMode {
+		children = m.renderFinder()
+	}
+	parts = append(parts,
+		children,
+		"",
+		m.renderHelp(),
+	)
 	if m.reloadFn != nil {
 		state := "on"
 		if m.watchPrompt {
@@ -403,9 +613,24 @@ func (m Model) View() string {
 	if m.filterMode {
 		parts = append(parts, m.helpStyle.Render(fmt.Sprintf("filter> %s (Enter: apply, Esc: clear)", m.filterQuery)))
 	}
+	if m.finderMode {
+		parts = append(parts, m.helpStyle.Render(fmt.Sprintf("find> %s (Enter: open, Esc: cancel)", m.finderQuery)))
+	}
 	return strings.Join(parts, "\n")
 }
 
+func (m Model) renderHelp() string {
+	k := m.keys
+	help := fmt.Sprintf("sort: %s  counter: %s  filter: %s  fold: %s  tree: %s | %s: move  %s: jump  %s: open  %s: back  %s: sort  %s: counter  %s: filter  %s: find  %s: fold  %s: tree  %s: files  %s: raw  %s: info  %s: quit",
+		m.sortLabel(), m.counterLabel(), m.filterLabel(), m.foldLabel(), onOff(m.tree),
+		k.pairLabel(actionUp, actionDown), k.pairLabel(actionTop, actionBottom), k.label(actionOpen), k.label(actionBack),
+		k.label(actionSort), k.label(actionCounter), k.label(actionFilter), k.label(actionFind), k.label(actionFold), k.label(actionTree), k.label(actionSource), k.label(actionRaw), k.label(actionInfo), k.label(actionQuit))
+	if len(m.source.Layers) > 1 {
+		help = fmt.Sprintf("layer: %s  %s  %s: layer", m.layerLabel(), help, k.label(actionLayer))
+	}
+	return m.helpStyle.Render(help)
+}
+
 type watchTickMsg struct{}
 
 type watchReloadMsg struct {
@@ -441,46 +666,137 @@ func watchReloadCmd(reloadFn func() (jacoco.Report, error)) tea.Cmd {
 func (m Model) renderBreadcrumb() string {
 	labels := []string{"Report"}
 	for i := 1; i < len(m.stack); i++ {
-		n := m.stack[i]
-		if n.packageIx >= 0 && n.packageIx < len(m.report.Packages) {
-			pkg := m.report.Packages[n.packageIx]
-			if n.kind == nodePackage {
-				labels = append(labels, pkg.Name)
-				continue
-			}
-			if n.classIx >= 0 && n.classIx < len(pkg.Classes) {
-				labels = append(labels, pkg.Name, pkg.Classes[n.classIx].Name)
-			}
+		if label, ok := m.nodeLabel(m.stack[i]); ok {
+			labels = append(labels, label)
 		}
 	}
 	if m.report.Name != "" {
 		labels[0] = fmt.Sprintf("Report(%s)", m.report.Name)
 	}
-	return m.titleStyle.Render(strings.Join(uniqueOrdered(labels), " > "))
+	return m.titleStyle.Render(strings.Join(labels, " > "))
 }
 
-func uniqueOrdered(items []string) []string {
-	seen := map[string]struct{}{}
-	out := make([]string, 0, len(items))
-	for _, item := range items {
-		if _, ok := seen[item]; ok {
-			continue
+func (m Model) nodeLabel(n navNode) (string, bool) {
+	switch n.kind {
+	case nodeGroup:
+		group, ok := m.groupAt(n.groupPath)
+		return m.treeName(n.groupPath[:len(n.groupPath)-1], group.Name), ok
+	case nodePackage:
+		pkg, ok := m.packageAt(n)
+		return m.treeName(n.groupPath, pkg.Name), ok
+	case nodeSource:
+		source, ok := m.sourceAt(n)
+		return sourceFileLabel(source), ok
+	case nodeClass:
+		class, ok := m.classAt(n)
+		return class.Name, ok
+	default:
+		return "", false
+	}
+}
+
+// groupAt resolves a group index path from the report root.
+func (m Model) groupAt(path []int) (jacoco.Group, bool) {
+	if len(path) == 0 {
+		return jacoco.Group{}, false
+	}
+	groups := m.report.Groups
+	var group jacoco.Group
+	for _, ix := range path {
+		if ix < 0 || ix >= len(groups) {
+			return jacoco.Group{}, false
 		}
-		seen[item] = struct{}{}
-		out = append(out, item)
+		group = groups[ix]
+		groups = group.Groups
 	}
-	return out
+	return group, true
+}
+
+// treeName shows a group or package of the package tree relative to the group
+// at path that holds it, such as b inside com/example. Other names are shown
+// as they are.
+func (m Model) treeName(path []int, name string) string {
+	if !m.tree {
+		return name
+	}
+	parent, ok := m.groupAt(path)
+	if !ok {
+		return name
+	}
+	if rest, ok := strings.CutPrefix(name, parent.Name); ok && len(rest) > 1 && (rest[0] == '/' || rest[0] == '.') {
+		return rest[1:]
+	}
+	return name
+}
+
+// containerAt returns the child groups and packages held by the report root
+// (empty path) or by the group at path.
+func (m Model) containerAt(path []int) ([]jacoco.Group, []jacoco.Package) {
+	if len(path) == 0 {
+		return m.report.Groups, m.report.Packages
+	}
+	group, ok := m.groupAt(path)
+	if !ok {
+		return nil, nil
+	}
+	return group.Groups, group.Packages
+}
+
+func (m Model) packageAt(n navNode) (jacoco.Package, bool) {
+	_, pkgs := m.containerAt(n.groupPath)
+	if n.packageIx < 0 || n.packageIx >= len(pkgs) {
+		return jacoco.Package{}, false
+	}
+	return pkgs[n.packageIx], true
+}
+
+func (m Model) sourceAt(n navNode) (jacoco.SourceFile, bool) {
+	pkg, ok := m.packageAt(n)
+	if !ok {
+		return jacoco.SourceFile{}, false
+	}
+	sources := jacoco.SourceFiles(pkg)
+	if n.sourceIx < 0 || n.sourceIx >= len(sources) {
+		return jacoco.SourceFile{}, false
+	}
+	return sources[n.sourceIx], true
+}
+
+func sourceFileLabel(source jacoco.SourceFile) string {
+	if source.Name == "" {
+		return "(no source file)"
+	}
+	return source.Name
+}
+
+func (m Model) classAt(n navNode) (jacoco.Class, bool) {
+	pkg, ok := m.packageAt(n)
+	if !ok || n.classIx < 0 || n.classIx >= len(pkg.Classes) {
+		return jacoco.Class{}, false
+	}
+	class := pkg.Classes[n.classIx]
+	for _, ix := range n.nested {
+		if ix < 0 || ix >= len(class.Nested) {
+			return jacoco.Class{}, false
+		}
+		class = class.Nested[ix]
+	}
+	return class, true
 }
 
 func (m Model) renderSummary() string {
-	lines := []string{m.headerStyle.Render(fmt.Sprintf("Summary (counter: %s)", m.counterLabel()))}
+	title := fmt.Sprintf("Summary (counter: %s)", m.counterLabel())
+	if m.layer >= 0 {
+		title = fmt.Sprintf("Summary (counter: %s, layer: %s)", m.counterLabel(), m.layerLabel())
+	}
+	lines := []string{m.headerStyle.Render(title)}
 	counters := m.currentCounters()
 	barWidth := m.summaryBarWidth()
 	for _, t := range []jacoco.CounterType{jacoco.CounterInstruction, jacoco.CounterBranch, jacoco.CounterLine, jacoco.CounterMethod} {
 		if c, ok := findCounter(counters, t); ok {
 			rate := c.CoverageRate()
 			line := fmt.Sprintf("%-12s %6.1f%%  %s", t, rate, bar(rate, barWidth))
-			lines = append(lines, m.styleForCoverage(rate).Render(line))
+			lines = append(lines, m.styleForCoverage(rate, t).Render(line))
 		}
 	}
 	if len(lines) == 1 {
@@ -489,6 +805,52 @@ func (m Model) renderSummary() string {
 	return strings.Join(lines, "\n")
 }
 
+// infoLines describes where the report came from: the input files with their
+// format, age and parse time, followed by the JaCoCo sessions that produced it.
+func (m Model) infoLines() []string {
+	lines := make([]string, 0, len(m.report.Inputs)+len(m.report.Sessions))
+	for _, in := range m.report.Inputs {
+		path := in.Path
+		if jacoco.IsStream(path) {
+			path = "(stdin)"
+		}
+		line := fmt.Sprintf("input   %s (%s, parsed in %s)", path, in.Format, in.ParseDuration.Round(time.Millisecond))
+		if !in.ModTime.IsZero() {
+			line = fmt.Sprintf("input   %s (%s, modified %s, %s ago, parsed in %s)",
+				path, in.Format, in.ModTime.Format(time.DateTime), formatAge(time.Since(in.ModTime)), in.ParseDuration.Round(time.Millisecond))
+		}
+		lines = append(lines, line)
+	}
+	for _, s := range m.report.Sessions {
+		lines = append(lines, fmt.Sprintf("session %s (start %s, dump %s)", s.ID, s.Start.Format(time.DateTime), s.Dump.Format(time.DateTime)))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "(no report metadata)")
+	}
+	return lines
+}
+
+func (m Model) renderInfo() string {
+	lines := []string{m.headerStyle.Render("Report info")}
+	for _, line := range m.infoLines() {
+		lines = append(lines, compactNameForDisplay(line, m.width))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
 func findCounter(counters []jacoco.Counter, t jacoco.CounterType) (jacoco.Counter, bool) {
 	for _, c := range counters {
 		if c.Type == t {
@@ -503,10 +865,18 @@ func (m Model) currentCounters() []jacoco.Counter {
 	switch current.kind {
 	case nodeReport:
 		return m.report.Counters
+	case nodeGroup:
+		group, _ := m.groupAt(current.groupPath)
+		return group.Counters
 	case nodePackage:
-		return m.report.Packages[current.packageIx].Counters
+		pkg, _ := m.packageAt(current)
+		return pkg.Counters
+	case nodeSource:
+		source, _ := m.sourceAt(current)
+		return source.Counters
 	case nodeClass:
-		return m.report.Packages[current.packageIx].Classes[current.classIx].Counters
+		class, _ := m.classAt(current)
+		return class.Counters
 	default:
 		return nil
 	}
@@ -554,7 +924,7 @@ func (m Model) renderChildren() string {
 		}
 		name := compactNameForDisplay(c.name, nameWidth)
 		line := fmt.Sprintf("%s %s %6.1f%% %s", marker, padRightDisplay(name, nameWidth), c.coverage, bar(c.coverage, barWidth))
-		style = style.Inherit(m.styleForCoverage(c.coverage))
+		style = style.Inherit(m.styleForCoverage(c.coverage, m.counterType))
 		lines = append(lines, style.Render(line))
 	}
 	return strings.Join(lines, "\n")
@@ -598,6 +968,9 @@ func (m Model) summaryLineCount() int {
 
 func (m Model) maxVisibleChildren() int {
 	available := m.height - (m.summaryLineCount() + 6)
+	if m.showInfo {
+		available -= len(m.infoLines()) + 2
+	}
 	if available < 1 {
 		return 1
 	}
@@ -751,6 +1124,7 @@ func ellipsizeMiddleDisplay(s string, width int) string {
 }
 
 type childRow struct {
+	kind     nodeKind
 	index    int
 	name     string
 	coverage float64
@@ -760,24 +1134,69 @@ func (m Model) currentChildren() []childRow {
 	current := m.stack[len(m.stack)-1]
 	rows := make([]childRow, 0)
 	switch current.kind {
-	case nodeReport:
-		rows = make([]childRow, 0, len(m.report.Packages))
-		for i, p := range m.report.Packages {
-			rows = append(rows, childRow{index: i, name: p.Name, coverage: coverageForType(p.Counters, m.counterType)})
+	case nodeReport, nodeGroup:
+		groups, pkgs := m.containerAt(current.groupPath)
+		rows = make([]childRow, 0, len(groups)+len(pkgs))
+		for i, g := range groups {
+			rows = append(rows, childRow{kind: nodeGroup, index: i, name: m.treeName(current.groupPath, g.Name), coverage: coverageForType(g.Counters, m.counterType)})
+		}
+		for i, p := range pkgs {
+			rows = append(rows, childRow{kind: nodePackage, index: i, name: m.treeName(current.groupPath, p.Name), coverage: coverageForType(p.Counters, m.counterType)})
 		}
 	case nodePackage:
-		pkg := m.report.Packages[current.packageIx]
+		pkg, _ := m.packageAt(current)
+		if m.bySource {
+			sources := jacoco.SourceFiles(pkg)
+			rows = make([]childRow, 0, len(sources))
+			for i, source := range sources {
+				rows = append(rows, childRow{kind: nodeSource, index: i, name: sourceFileLabel(source), coverage: coverageForType(source.Counters, m.counterType)})
+			}
+			break
+		}
 		rows = make([]childRow, 0, len(pkg.Classes))
 		for i, c := range pkg.Classes {
-			rows = append(rows, childRow{index: i, name: c.Name, coverage: coverageForType(c.Counters, m.counterType)})
+			rows = append(rows, childRow{kind: nodeClass, index: i, name: c.Name, coverage: coverageForType(c.Counters, m.counterType)})
+		}
+	case nodeSource:
+		// The classes of the file and their methods. Methods carry the class
+		// name so that sorting by name keeps them below their class.
+		pkg, _ := m.packageAt(current)
+		source, _ := m.sourceAt(current)
+		for _, ix := range source.Classes {
+			c := pkg.Classes[ix]
+			rows = append(rows, childRow{kind: nodeClass, index: ix, name: c.Name, coverage: coverageForType(c.Counters, m.counterType)})
+		}
+		for _, ix := range source.Classes {
+			c := pkg.Classes[ix]
+			for i, method := range c.Methods {
+				if m.hiddenMethod(c, method) {
+					continue
+				}
+				rows = append(rows, childRow{
+					index:    i,
+					name:     c.Name + "." + methodDisplayName(c, method, m.config.QualifiedTypes, m.raw),
+					coverage: coverageForType(method.Counters, m.counterType),
+				})
+			}
 		}
 	case nodeClass:
-		class := m.report.Packages[current.packageIx].Classes[current.classIx]
-		rows = make([]childRow, 0, len(class.Methods))
+		class, _ := m.classAt(current)
+		rows = make([]childRow, 0, len(class.Nested)+len(class.Methods))
+		for i, nested := range class.Nested {
+			rows = append(rows, childRow{
+				kind:     nodeClass,
+				index:    i,
+				name:     nestedClassName(class, nested),
+				coverage: coverageForType(nested.Counters, m.counterType),
+			})
+		}
 		for i, method := range class.Methods {
+			if m.hiddenMethod(class, method) {
+				continue
+			}
 			rows = append(rows, childRow{
 				index:    i,
-				name:     methodDisplayName(method),
+				name:     methodDisplayName(class, method, m.config.QualifiedTypes, m.raw),
 				coverage: coverageForType(method.Counters, m.counterType),
 			})
 		}
@@ -852,6 +1271,17 @@ func (m Model) counterLabel() string {
 	return strings.ToLower(string(m.counterType))
 }
 
+func (m Model) foldLabel() string {
+	return onOff(m.fold)
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
 func (m Model) filterLabel() string {
 	if strings.TrimSpace(m.filterQuery) == "" {
 		return "off"
@@ -859,17 +1289,77 @@ func (m Model) filterLabel() string {
 	return m.filterQuery
 }
 
-func methodDisplayName(method jacoco.Method) string {
-	label := method.Name
-	if method.Desc != "" {
-		label += method.Desc
+// hiddenMethod reports whether a method is left out of the method lists.
+// Lambda bodies are user code and stay listed; fold mode merges them into the
+// method that declares them.
+func (m Model) hiddenMethod(class jacoco.Class, method jacoco.Method) bool {
+	if m.config.ShowSynthetic {
+		return false
+	}
+	return jacoco.IsAccessorMethod(method) || jacoco.IsBridgeMethod(class, method)
+}
+
+// nestedClassName shows a folded class relative to its top-level class, such
+// as $Builder or $1.
+func nestedClassName(outer, nested jacoco.Class) string {
+	if rest, ok := strings.CutPrefix(nested.Name, outer.Name); ok && rest != "" {
+		return rest
+	}
+	return path.Base(nested.Name)
+}
+
+// methodDisplayName shows a method the way its source declares it when the
+// descriptor is a JVM one, such as process(List, int): String L42.
+// Constructors take the class name and static initializers read static {}.
+// Other descriptors are shown as they are, and mangled C++, Rust and Swift
+// symbols are demangled unless raw is set.
+func methodDisplayName(class jacoco.Class, method jacoco.Method, qualified, raw bool) string {
+	params, ret, ok := jacoco.DecodeDescriptor(method.Desc, qualified)
+	if !ok {
+		name := method.Name
+		if !raw {
+			name, _ = demangle.Demangle(name)
+		}
+		label := name + method.Desc
+		if method.Line > 0 {
+			label += fmt.Sprintf(":%d", method.Line)
+		}
+		return label
+	}
+
+	var label string
+	switch method.Name {
+	case "<clinit>":
+		label = "static {}"
+	case "<init>":
+		label = fmt.Sprintf("%s(%s)", classSimpleName(class.Name, qualified), strings.Join(params, ", "))
+	default:
+		label = fmt.Sprintf("%s(%s)", method.Name, strings.Join(params, ", "))
+		if ret != "void" {
+			label += ": " + ret
+		}
 	}
 	if method.Line > 0 {
-		label += fmt.Sprintf(":%d", method.Line)
+		label += fmt.Sprintf(" L%d", method.Line)
 	}
 	return label
 }
 
+// classSimpleName is the name a constructor is declared with: Bar for
+// com/example/Foo$Bar. Anonymous classes keep their outer name, as in Foo$1.
+func classSimpleName(name string, qualified bool) string {
+	if qualified {
+		return strings.ReplaceAll(name, "/", ".")
+	}
+	name = path.Base(name)
+	if i := strings.LastIndexByte(name, '$'); i >= 0 && i+1 < len(name) {
+		if rest := name[i+1:]; strings.Trim(rest, "0123456789") != "" {
+			return rest
+		}
+	}
+	return name
+}
+
 func bar(percentage float64, width int) string {
 	if width <= 0 {
 		return ""
@@ -902,16 +1392,25 @@ func bandForCoverage(rate float64, threshold int) coverageBand {
 	return bandLow
 }
 
-func (m Model) styleForCoverage(rate float64) lipgloss.Style {
+// thresholdFor returns the threshold of a counter type, falling back to the
+// global threshold.
+func (m Model) thresholdFor(t jacoco.CounterType) int {
+	if threshold, ok := m.config.Thresholds[t]; ok {
+		return threshold
+	}
+	return m.config.Threshold
+}
+
+func (m Model) styleForCoverage(rate float64, t jacoco.CounterType) lipgloss.Style {
 	if m.config.NoColor {
 		return lipgloss.NewStyle()
 	}
-	switch bandForCoverage(rate, m.config.Threshold) {
+	switch bandForCoverage(rate, m.thresholdFor(t)) {
 	case bandHigh:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(draculaGreen))
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(m.config.Theme.High))
 	case bandMid:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(draculaYellow))
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(m.config.Theme.Mid))
 	default:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(draculaRed))
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(m.config.Theme.Low))
 	}
 }
@@ -2,8 +2,10 @@ package tui
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -68,6 +70,48 @@ func TestDrillDownAndBack(t *testing.T) {
 	}
 }
 
+func TestDrillDownThroughGroups(t *testing.T) {
+	report := jacoco.Report{
+		Name: "aggregate",
+		Groups: []jacoco.Group{{
+			Name:     "module-a",
+			Counters: []jacoco.Counter{{Type: jacoco.CounterInstruction, Missed: 1, Covered: 9}},
+			Groups: []jacoco.Group{{
+				Name:     "nested",
+				Packages: sampleReport().Packages,
+			}},
+		}},
+	}
+	m := NewModel(report, Config{Sort: "name"})
+
+	rows := m.currentChildren()
+	if len(rows) != 1 || rows[0].kind != nodeGroup || rows[0].name != "module-a" {
+		t.Fatalf("unexpected report children: %+v", rows)
+	}
+
+	m.applyKey("enter")
+	m.applyKey("enter")
+	if m.current().kind != nodeGroup || len(m.current().groupPath) != 2 {
+		t.Fatalf("expected nested group level, stack=%+v", m.stack)
+	}
+
+	m.applyKey("enter")
+	m.applyKey("enter")
+	if m.current().kind != nodeClass {
+		t.Fatalf("expected class level, stack=%+v", m.stack)
+	}
+	if got := m.renderBreadcrumb(); !strings.Contains(got, "module-a > nested > com/example > UserService") {
+		t.Fatalf("unexpected breadcrumb: %q", got)
+	}
+
+	m.applyKey("b")
+	m.applyKey("b")
+	m.applyKey("b")
+	if m.current().kind != nodeGroup || m.current().groupPath[0] != 0 {
+		t.Fatalf("expected back to top group, stack=%+v", m.stack)
+	}
+}
+
 func TestMethodDisplayNameIncludesSignatureAndLine(t *testing.T) {
 	report := jacoco.Report{
 		Packages: []jacoco.Package{{
@@ -93,11 +137,104 @@ func TestMethodDisplayNameIncludesSignatureAndLine(t *testing.T) {
 	if len(rows) != 1 {
 		t.Fatalf("method row count mismatch: %d", len(rows))
 	}
-	if rows[0].name != "find(I)Ljava/lang/String;:42" {
+	if rows[0].name != "find(int): String L42" {
 		t.Fatalf("unexpected method label: %q", rows[0].name)
 	}
 }
 
+func TestMethodDisplayNameDecodesDescriptors(t *testing.T) {
+	tests := []struct {
+		class     string
+		method    jacoco.Method
+		qualified bool
+		want      string
+	}{
+		{class: "com/example/Foo", method: jacoco.Method{Name: "process", Desc: "(Ljava/util/List;I)Ljava/lang/String;"}, want: "process(List, int): String"},
+		{class: "com/example/Foo", method: jacoco.Method{Name: "process", Desc: "(Ljava/util/List;I)Ljava/lang/String;"}, qualified: true, want: "process(java.util.List, int): java.lang.String"},
+		{class: "com/example/Foo", method: jacoco.Method{Name: "put", Desc: "([[JLjava/util/Map$Entry;)V", Line: 7}, want: "put(long[][], Map.Entry) L7"},
+		{class: "com/example/Foo$Builder", method: jacoco.Method{Name: "<init>", Desc: "(Lcom/example/Foo;)V"}, want: "Builder(Foo)"},
+		{class: "com/example/Foo$1", method: jacoco.Method{Name: "<init>", Desc: "()V"}, want: "Foo$1()"},
+		{class: "com/example/Foo", method: jacoco.Method{Name: "<init>", Desc: "()V"}, qualified: true, want: "com.example.Foo()"},
+		{class: "com/example/Foo", method: jacoco.Method{Name: "<clinit>", Desc: "()V", Line: 3}, want: "static {} L3"},
+		{class: "src/app.ts", method: jacoco.Method{Name: "render", Line: 12}, want: "render:12"},
+	}
+	for _, tc := range tests {
+		if got := methodDisplayName(jacoco.Class{Name: tc.class}, tc.method, tc.qualified, false); got != tc.want {
+			t.Fatalf("%s%s: got %q want %q", tc.method.Name, tc.method.Desc, got, tc.want)
+		}
+	}
+}
+
+func TestRawKeyTogglesDemangling(t *testing.T) {
+	report := jacoco.Report{Packages: []jacoco.Package{{
+		Name: "src",
+		Classes: []jacoco.Class{{
+			Name:    "src/foo.cpp",
+			Methods: []jacoco.Method{{Name: "_ZN3foo3barEi", Line: 8}},
+		}},
+	}}}
+	m := NewModel(report, Config{Sort: "name"})
+	m.applyKey("enter")
+	m.applyKey("enter")
+
+	if rows := m.currentChildren(); len(rows) != 1 || rows[0].name != "foo::bar(int):8" {
+		t.Fatalf("method should be demangled: %+v", rows)
+	}
+	m.applyKey("m")
+	if rows := m.currentChildren(); rows[0].name != "_ZN3foo3barEi:8" {
+		t.Fatalf("raw key should show the mangled name: %q", rows[0].name)
+	}
+}
+
+func TestTreeKeyNestsPackages(t *testing.T) {
+	report := jacoco.Report{Packages: []jacoco.Package{
+		{Name: "com/example/a", Counters: []jacoco.Counter{{Type: jacoco.CounterInstruction, Missed: 1, Covered: 1}}},
+		{Name: "com/example/b", Counters: []jacoco.Counter{{Type: jacoco.CounterInstruction, Missed: 0, Covered: 2}}},
+	}}
+	m := NewModel(report, Config{Sort: "name"})
+	m.applyKey("enter")
+	m.applyKey("t")
+
+	rows := m.currentChildren()
+	if len(m.stack) != 1 || len(rows) != 1 || rows[0].kind != nodeGroup || rows[0].name != "com/example" || rows[0].coverage != 75 {
+		t.Fatalf("tree should start at the report with one group: %d %+v", len(m.stack), rows)
+	}
+	m.applyKey("enter")
+	m.applyKey("down")
+	m.applyKey("enter")
+	if got := m.renderBreadcrumb(); !strings.Contains(got, "com/example > b") {
+		t.Fatalf("breadcrumb mismatch: %q", got)
+	}
+	m.applyKey("t")
+	if rows := m.currentChildren(); len(m.stack) != 1 || len(rows) != 2 || rows[0].name != "com/example/a" {
+		t.Fatalf("flat list should come back: %+v", rows)
+	}
+}
+
+func TestFilterMatchesReadableSignature(t *testing.T) {
+	report := jacoco.Report{Packages: []jacoco.Package{{
+		Name: "pkg",
+		Classes: []jacoco.Class{{
+			Name: "pkg/C",
+			Methods: []jacoco.Method{
+				{Name: "find", Desc: "(I)Ljava/lang/String;"},
+				{Name: "load", Desc: "(Ljava/lang/String;)V"},
+			},
+		}},
+	}}}
+	m := NewModel(report, Config{Sort: "name"})
+	m.applyKey("enter")
+	m.applyKey("enter")
+	m.startFilter()
+	for _, r := range "(int" {
+		m.applyKey(string(r))
+	}
+	rows := m.currentChildren()
+	if len(rows) != 1 || rows[0].name != "find(int): String" {
+		t.Fatalf("filter should match the readable form: %+v", rows)
+	}
+}
+
 func TestBarWidth(t *testing.T) {
 	got := bar(50, 10)
 	if got != "█████░░░░░" {
@@ -245,6 +382,32 @@ func TestFilterPromptVisibleInFilterMode(t *testing.T) {
 	}
 }
 
+func TestInfoPanelToggle(t *testing.T) {
+	report := sampleReport()
+	report.Inputs = []jacoco.Input{{
+		Path:    "target/site/jacoco/jacoco.xml",
+		Format:  jacoco.FormatJaCoCo,
+		ModTime: time.Now().Add(-3 * time.Hour),
+	}}
+	report.Sessions = []jacoco.SessionInfo{{ID: "host-1234", Start: time.Now(), Dump: time.Now()}}
+	m := NewModel(report, Config{Sort: "name", NoColor: true})
+
+	if strings.Contains(m.View(), "Report info") {
+		t.Fatal("info panel should be hidden by default")
+	}
+	m.applyKey("i")
+	view := m.View()
+	for _, want := range []string{"Report info", "target/site/jacoco/jacoco.xml", "jacoco", "3h ago", "session host-1234"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("info panel missing %q: %q", want, view)
+		}
+	}
+	m.applyKey("i")
+	if strings.Contains(m.View(), "Report info") {
+		t.Fatal("info panel should be hidden after second toggle")
+	}
+}
+
 func TestBandForCoverage(t *testing.T) {
 	if bandForCoverage(79.9, 80) != bandLow {
 		t.Fatal("expected low band")
@@ -260,6 +423,19 @@ func TestBandForCoverage(t *testing.T) {
 	}
 }
 
+func TestThresholdPerCounterType(t *testing.T) {
+	m := NewModel(sampleReport(), Config{
+		Threshold:  80,
+		Thresholds: map[jacoco.CounterType]int{jacoco.CounterBranch: 60},
+	})
+	if got := m.thresholdFor(jacoco.CounterBranch); got != 60 {
+		t.Fatalf("branch threshold mismatch: %d", got)
+	}
+	if got := m.thresholdFor(jacoco.CounterLine); got != 80 {
+		t.Fatalf("line threshold should fall back to the global one: %d", got)
+	}
+}
+
 func TestCursorMoveBounds(t *testing.T) {
 	report := jacoco.Report{
 		Packages: []jacoco.Package{{Name: "a"}, {Name: "b"}},
@@ -574,3 +750,175 @@ func TestWatchFlagAutoReloadsWithoutConfirmation(t *testing.T) {
 		t.Fatal("reload function should be called in auto watch mode")
 	}
 }
+
+func TestLayerToggleCyclesViews(t *testing.T) {
+	class := func(missed, covered int) jacoco.Report {
+		counters := []jacoco.Counter{{Type: jacoco.CounterInstruction, Missed: missed, Covered: covered}}
+		return jacoco.Report{
+			Inputs: []jacoco.Input{{Path: fmt.Sprintf("r%d.xml", covered), Format: jacoco.FormatJaCoCo}},
+			Packages: []jacoco.Package{{
+				Name:     "p",
+				Classes:  []jacoco.Class{{Name: "p/A", Counters: counters}},
+				Counters: counters,
+			}},
+			Counters: counters,
+		}
+	}
+	merged := jacoco.MergeReportsWith(jacoco.MergeMax, class(8, 2), class(4, 6))
+	m := NewModel(merged, Config{Sort: "name", NoColor: true})
+
+	rate := func() float64 {
+		c, _ := findCounter(m.currentCounters(), jacoco.CounterInstruction)
+		return c.CoverageRate()
+	}
+	if rate() != 60 || !strings.Contains(m.View(), "layer: union") {
+		t.Fatalf("expected union view first, got %.1f", rate())
+	}
+	m.applyKey("l")
+	if rate() != 20 || !strings.Contains(m.View(), "layer: r2.xml") {
+		t.Fatalf("expected first layer, got %.1f", rate())
+	}
+	m.applyKey("l")
+	if rate() != 60 {
+		t.Fatalf("expected second layer, got %.1f", rate())
+	}
+	m.applyKey("l")
+	m.applyKey("l")
+	if rate() != 40 || !strings.Contains(m.View(), "only r6.xml") {
+		t.Fatalf("expected coverage only by the second layer, got %.1f", rate())
+	}
+	m.applyKey("l")
+	if rate() != 60 {
+		t.Fatalf("expected union view after a full cycle, got %.1f", rate())
+	}
+}
+
+func TestLayerToggleIgnoredForSingleReport(t *testing.T) {
+	m := NewModel(sampleReport(), Config{Sort: "name", NoColor: true})
+	m.applyKey("l")
+	if m.layer != -1 || strings.Contains(m.View(), "layer:") {
+		t.Fatal("layer toggle should be inactive without merged inputs")
+	}
+}
+
+func TestFoldToggleShowsNestedClasses(t *testing.T) {
+	report := jacoco.Report{Packages: []jacoco.Package{{
+		Name: "com/example",
+		Classes: []jacoco.Class{
+			{Name: "com/example/Foo", Counters: []jacoco.Counter{{Type: jacoco.CounterInstruction, Covered: 4}},
+				Methods: []jacoco.Method{
+					{Name: "compareTo", Desc: "(Lcom/example/Foo;)I"},
+					{Name: "compareTo", Desc: "(Ljava/lang/Object;)I"},
+				}},
+			{Name: "com/example/Foo$Bar", Counters: []jacoco.Counter{{Type: jacoco.CounterInstruction, Missed: 4}},
+				Methods: []jacoco.Method{{Name: "run"}}},
+		},
+	}}}
+	m := NewModel(report, Config{Threshold: 80, Fold: true})
+
+	m.applyKey("enter")
+	if n := m.visibleChildCount(); n != 1 {
+		t.Fatalf("nested class should be folded, got %d classes", n)
+	}
+	m.applyKey("enter")
+	rows := m.currentChildren()
+	if len(rows) != 2 || rows[0].name != "$Bar" || rows[1].name != "compareTo(Foo): int" {
+		t.Fatalf("class rows mismatch (bridge method should be hidden): %+v", rows)
+	}
+	if rows[0].coverage != 0 {
+		t.Fatalf("nested class coverage mismatch: %v", rows[0].coverage)
+	}
+	m.applyKey("enter")
+	if label, _ := m.nodeLabel(*m.current()); label != "com/example/Foo$Bar" || m.visibleChildCount() != 1 {
+		t.Fatalf("expected nested class level, got %s", label)
+	}
+
+	m.applyKey("z")
+	if len(m.stack) != 2 || m.current().kind != nodePackage || m.visibleChildCount() != 2 {
+		t.Fatalf("unfolding should return to the package: %+v", m.stack)
+	}
+	if !strings.Contains(m.renderHelp(), "fold: off") {
+		t.Fatal("help should show the fold state")
+	}
+}
+
+func TestHiddenMethods(t *testing.T) {
+	class := jacoco.Class{Name: "com/example/Foo", Methods: []jacoco.Method{
+		{Name: "equals", Desc: "(Lcom/example/Foo;)Z"},
+		{Name: "equals", Desc: "(Ljava/lang/Object;)Z"},
+		{Name: "access$000", Desc: "()V"},
+		{Name: "run", Desc: "()V"},
+		{Name: "lambda$run$0", Desc: "()V"},
+	}}
+	report := jacoco.Report{Packages: []jacoco.Package{{Name: "com/example", Classes: []jacoco.Class{class}}}}
+	names := func(cfg Config) []string {
+		m := NewModel(report, cfg)
+		m.applyKey("enter")
+		m.applyKey("enter")
+		var out []string
+		for _, row := range m.currentChildren() {
+			out = append(out, row.name)
+		}
+		return out
+	}
+
+	got := names(Config{Sort: "name"})
+	if len(got) != 3 || got[0] != "equals(Foo): boolean" || !strings.HasPrefix(got[1], "lambda$run$0") || got[2] != "run()" {
+		t.Fatalf("bridges and accessors should be hidden and lambdas listed: %v", got)
+	}
+	if got := names(Config{Sort: "name", Fold: true}); len(got) != 2 || got[1] != "run()" {
+		t.Fatalf("fold should merge the lambda into run: %v", got)
+	}
+	if got := names(Config{Sort: "name", ShowSynthetic: true}); len(got) != 5 {
+		t.Fatalf("show-synthetic should list accessors and bridges: %v", got)
+	}
+}
+
+func TestSourceFileView(t *testing.T) {
+	instr := func(missed, covered int) []jacoco.Counter {
+		return []jacoco.Counter{{Type: jacoco.CounterInstruction, Missed: missed, Covered: covered}}
+	}
+	report := jacoco.Report{Packages: []jacoco.Package{{
+		Name: "com/example",
+		Classes: []jacoco.Class{
+			{Name: "com/example/FooKt", SourceFileName: "Foo.kt", Counters: instr(1, 1),
+				Methods: []jacoco.Method{{Name: "main", Counters: instr(1, 1)}}},
+			{Name: "com/example/Bar", SourceFileName: "Bar.kt", Counters: instr(0, 2)},
+			{Name: "com/example/Foo", SourceFileName: "Foo.kt", Counters: instr(0, 2)},
+		},
+	}}}
+	m := NewModel(report, Config{Threshold: 80})
+
+	m.applyKey("f")
+	if m.bySource {
+		t.Fatal("source view should toggle only on the package screen")
+	}
+	m.applyKey("enter")
+	m.applyKey("f")
+	rows := m.currentChildren()
+	if len(rows) != 2 || rows[0].name != "Bar.kt" || rows[1].name != "Foo.kt" || rows[1].coverage != 75 {
+		t.Fatalf("source rows mismatch: %+v", rows)
+	}
+
+	m.applyKey("j")
+	m.applyKey("enter")
+	if label, _ := m.nodeLabel(*m.current()); label != "Foo.kt" {
+		t.Fatalf("expected source level, got %q", label)
+	}
+	var names []string
+	for _, row := range m.currentChildren() {
+		names = append(names, row.name)
+	}
+	if strings.Join(names, ",") != "com/example/Foo,com/example/FooKt,com/example/FooKt.main" {
+		t.Fatalf("source children mismatch: %v", names)
+	}
+
+	m.applyKey("j")
+	m.applyKey("enter")
+	if label, _ := m.nodeLabel(*m.current()); label != "com/example/FooKt" || m.visibleChildCount() != 1 {
+		t.Fatalf("expected class level, got %q", label)
+	}
+	if !strings.Contains(m.renderBreadcrumb(), "com/example > Foo.kt > com/example/FooKt") {
+		t.Fatalf("breadcrumb mismatch: %s", m.renderBreadcrumb())
+	}
+}
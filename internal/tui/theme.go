@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Theme holds the colors of the UI as hex codes (#RRGGBB) or ANSI 256 color
+// numbers. High, Mid and Low color coverage rates by threshold band.
+type Theme struct {
+	Title  string
+	Header string
+	Cursor string
+	Help   string
+	High   string
+	Mid    string
+	Low    string
+}
+
+// DefaultTheme is the Dracula palette.
+func DefaultTheme() Theme {
+	return Theme{
+		Title:  draculaPurple,
+		Header: draculaCyan,
+		Cursor: draculaPink,
+		Help:   draculaComment,
+		High:   draculaGreen,
+		Mid:    draculaYellow,
+		Low:    draculaRed,
+	}
+}
+
+var hexColorRe = regexp.MustCompile(`^#(?:[0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+
+// NewTheme applies color overrides, keyed by lower-case field name, to the
+// default theme.
+func NewTheme(overrides map[string]string) (Theme, error) {
+	theme := DefaultTheme()
+	fields := map[string]*string{
+		"title":  &theme.Title,
+		"header": &theme.Header,
+		"cursor": &theme.Cursor,
+		"help":   &theme.Help,
+		"high":   &theme.High,
+		"mid":    &theme.Mid,
+		"low":    &theme.Low,
+	}
+	for name, color := range overrides {
+		field, ok := fields[name]
+		if !ok {
+			names := make([]string, 0, len(fields))
+			for n := range fields {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return Theme{}, fmt.Errorf("不明なテーマ項目です: %s（%s）", name, strings.Join(names, " / "))
+		}
+		if !validColor(color) {
+			return Theme{}, fmt.Errorf("%s の色は #RRGGBB または 0-255 で指定してください: %s", name, color)
+		}
+		*field = color
+	}
+	return theme, nil
+}
+
+func validColor(color string) bool {
+	if hexColorRe.MatchString(color) {
+		return true
+	}
+	n, err := strconv.Atoi(color)
+	return err == nil && n >= 0 && n <= 255
+}
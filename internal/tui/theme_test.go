@@ -0,0 +1,23 @@
+package tui
+
+import "testing"
+
+func TestNewThemeOverridesColors(t *testing.T) {
+	theme, err := NewTheme(map[string]string{"high": "#00ff00", "low": "196"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if theme.High != "#00ff00" || theme.Low != "196" || theme.Mid != DefaultTheme().Mid {
+		t.Fatalf("unexpected theme: %+v", theme)
+	}
+
+	for _, overrides := range []map[string]string{
+		{"background": "#000000"},
+		{"high": "green"},
+		{"low": "256"},
+	} {
+		if _, err := NewTheme(overrides); err == nil {
+			t.Fatalf("expected error for %v", overrides)
+		}
+	}
+}